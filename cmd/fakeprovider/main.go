@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/fakeprovider"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// Serves stand-in rates for the rate ingestion, e.g.
+// go run cmd/fakeprovider/main.go -mode randomWalk -error-rate 0.2
+// and RATE_PROVIDERS=fake=http://localhost:8081/rates
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	base := flag.String("base", "USD", "base currency of the rates")
+	rates := flag.String("rates", "EUR=0.92,GBP=0.79,JPY=149.5,CHF=0.86,CAD=1.37", "comma separated rates against the base currency")
+	mode := flag.String("mode", string(fakeprovider.ModeFixed), "fixed or randomWalk")
+	volatility := flag.Float64("volatility", 0.5, "standard deviation of a random walk step, in percent")
+	seed := flag.Uint64("seed", 1, "seed of the random walk and faults")
+	latency := flag.Duration("latency", 0, "delay of every response")
+	errorRate := flag.Float64("error-rate", 0, "probability of responding with an error")
+	malformedRate := flag.Float64("malformed-rate", 0, "probability of responding with a malformed payload")
+	flag.Parse()
+
+	if !slices.Contains(fakeprovider.Modes, fakeprovider.Mode(*mode)) {
+		slog.Error("Invalid mode", "mode", *mode)
+		os.Exit(2)
+	}
+
+	parsedRates, err := parseRates(*rates)
+
+	if err != nil {
+		slog.Error("Invalid rates", "error", err)
+		os.Exit(2)
+	}
+
+	server := fakeprovider.New(fakeprovider.Options{
+		Base:       *base,
+		Rates:      parsedRates,
+		Mode:       fakeprovider.Mode(*mode),
+		Volatility: *volatility,
+		Seed:       *seed,
+		Faults: fakeprovider.Faults{
+			Latency:       *latency,
+			ErrorRate:     *errorRate,
+			MalformedRate: *malformedRate,
+		},
+	})
+
+	slog.Info("Starting fake rate provider", "addr", *addr)
+
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// parseRates parses rates like EUR=0.92,GBP=0.79
+func parseRates(value string) (map[string]decimal.Decimal, error) {
+	rates := make(map[string]decimal.Decimal)
+
+	for _, entry := range strings.Split(value, ",") {
+		code, rateStr, _ := strings.Cut(strings.TrimSpace(entry), "=")
+
+		if err := validator.ValidateCurrencyCode(code); err != nil {
+			return nil, err
+		}
+
+		rate, err := decimal.NewFromString(rateStr)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rates[code] = rate
+	}
+
+	return rates, nil
+}
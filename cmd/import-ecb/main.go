@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/config"
+	"github.com/krios2146/currency-exchange-api-go/internal/db"
+	"github.com/krios2146/currency-exchange-api-go/internal/importer"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+)
+
+// Imports the ECB euro foreign exchange reference rates from a file or URL,
+// e.g. go run cmd/import-ecb/main.go https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml
+func main() {
+	if len(os.Args) != 2 {
+		slog.Error("Usage: import-ecb <file or url>")
+		os.Exit(2)
+	}
+
+	source := os.Args[1]
+	cfg := config.Load()
+
+	if cfg.RateApprovalRequired {
+		slog.Error("ECB imports are disabled while rate changes require approval")
+		os.Exit(1)
+	}
+
+	validator.RejectUnknownCurrencyCodes(cfg.UnknownCurrencyCodesRejected)
+
+	days, err := loadECB(source, cfg.RateProviderTimeout)
+
+	if err != nil {
+		slog.Error("Couldn't load ECB rates", "source", source, "error", err)
+		os.Exit(1)
+	}
+
+	database := db.NewSqliteDBConnection()
+	defer database.Close()
+
+	ecbImporter := importer.NewECBImporter(
+		store.NewExchangeRateStore(database),
+		store.NewCurrencyStore(database),
+		cfg.MaxRateDeviation,
+	)
+
+	if _, err := ecbImporter.Import(days); err != nil {
+		slog.Error("Couldn't import ECB rates", "source", source, "error", err)
+		os.Exit(1)
+	}
+}
+
+func loadECB(source string, timeout time.Duration) ([]provider.ECBDay, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: timeout}
+		return provider.FetchECB(context.Background(), client, source)
+	}
+
+	file, err := os.Open(source)
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return provider.ParseECB(file)
+}
@@ -2,4 +2,7 @@ module github.com/krios2146/currency-exchange-api-go
 
 go 1.22.5
 
-require github.com/mattn/go-sqlite3 v1.14.22 // indirect
+require (
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/shopspring/decimal v1.4.0
+)
@@ -6,48 +6,133 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/krios2146/currency-exchange-api-go/internal/config"
 	"github.com/krios2146/currency-exchange-api-go/internal/handler"
+	"github.com/krios2146/currency-exchange-api-go/internal/importer"
+	"github.com/krios2146/currency-exchange-api-go/internal/job"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
 	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
 )
 
 type Server struct {
-	db *sql.DB
+	db     *sql.DB
+	config *config.Config
 }
 
 type CurrenciesHandler struct {
 	db *sql.DB
 }
 
-func NewServer(db *sql.DB) *Server {
+func NewServer(db *sql.DB, config *config.Config) *Server {
 	return &Server{
-		db: db,
+		db:     db,
+		config: config,
 	}
 }
 
 func (s *Server) Run() {
 	mux := http.NewServeMux()
 
+	validator.RejectUnknownCurrencyCodes(s.config.UnknownCurrencyCodesRejected)
+
 	slog.Debug("Registering handlers")
 
 	currencyStore := store.NewCurrencyStore(s.db)
 	currencyHandler := handler.NewCurrencyHandler(currencyStore)
 
 	exchangeRatesStore := store.NewExchangeRateStore(s.db)
-	exchangeRatesHander := handler.NewExchangeRateHandler(exchangeRatesStore, currencyStore)
+	rateChangeStore := store.NewRateChangeStore(s.db)
+	exchangeRatesHander := handler.NewExchangeRateHandler(
+		exchangeRatesStore,
+		currencyStore,
+		rateChangeStore,
+		s.config.Staleness,
+		s.config.MaxRateDeviation,
+		s.config.RateApprovalRequired,
+	)
+	rateChangeHandler := handler.NewRateChangeHandler(rateChangeStore, currencyStore)
+
+	ecbImportHandler := handler.NewECBImportHandler(
+		importer.NewECBImporter(exchangeRatesStore, currencyStore, s.config.MaxRateDeviation),
+		s.config.RateApprovalRequired,
+	)
+
+	rateProviders := s.rateProviders()
+	rateProviderHandler := handler.NewRateProviderHandler(rateProviders)
+
+	consistencyHandler := handler.NewConsistencyHandler(exchangeRatesStore, currencyStore, s.config.ConsistencyThreshold)
+
+	feeRuleStore := store.NewFeeRuleStore(s.db)
+	feeRuleHandler := handler.NewFeeRuleHandler(feeRuleStore, currencyStore)
+
+	exchangeHandler := handler.NewExchangeHandler(exchangeRatesStore, currencyStore, feeRuleStore, s.config.PivotCurrencies, s.config.Staleness)
 
-	exchangeHandler := handler.NewExchangeHandler(exchangeRatesStore, currencyStore)
+	quoteStore := store.NewQuoteStore(s.db)
+	quoteHandler := handler.NewQuoteHandler(quoteStore, currencyStore, exchangeHandler, s.config.QuoteTTL)
 
 	mux.HandleFunc("GET /currencies", currencyHandler.GetAllCurrencies)
 	mux.HandleFunc("GET /currency/{code}", currencyHandler.GetCurrencyByCode)
 	mux.HandleFunc("GET /currency/", currencyHandler.GetCurrencyByCode)
+	mux.HandleFunc("GET /currencies/catalog", currencyHandler.GetCatalog)
 	mux.HandleFunc("POST /currencies", currencyHandler.AddCurrency)
+	mux.HandleFunc("POST /currencies/{code}/import", currencyHandler.ImportCurrency)
+	mux.HandleFunc("PATCH /currency/{code}", currencyHandler.UpdateCurrency)
+	mux.HandleFunc("DELETE /currency/{code}", currencyHandler.DeleteCurrency)
 
 	mux.HandleFunc("GET /exchangeRates", exchangeRatesHander.GetAllExchangeRates)
 	mux.HandleFunc("GET /exchangeRate/{code_pair}", exchangeRatesHander.GetExchangeRateByCodes)
+	mux.HandleFunc("GET /exchangeRate/{code_pair}/series", exchangeRatesHander.GetExchangeRateSeries)
 	mux.HandleFunc("POST /exchangeRates", exchangeRatesHander.AddExchangeRate)
 	mux.HandleFunc("PATCH /exchangeRate/{code_pair}", exchangeRatesHander.UpdateExchangeRate)
+	mux.HandleFunc("DELETE /exchangeRate/{code_pair}", exchangeRatesHander.DeleteExchangeRate)
+	mux.HandleFunc("GET /exchangeRates/scheduled", exchangeRatesHander.GetScheduledExchangeRates)
+	mux.HandleFunc("GET /exchangeRates/consistency", consistencyHandler.GetConsistencyReport)
+	mux.HandleFunc("POST /exchangeRates/import/ecb", ecbImportHandler.ImportECB)
+
+	mux.HandleFunc("GET /rateChanges", rateChangeHandler.GetAllRateChanges)
+	mux.HandleFunc("GET /rateChange/{id}", rateChangeHandler.GetRateChangeById)
+	mux.HandleFunc("POST /rateChange/{id}/approve", rateChangeHandler.ApproveRateChange)
+	mux.HandleFunc("POST /rateChange/{id}/reject", rateChangeHandler.RejectRateChange)
+
+	mux.HandleFunc("GET /rateProviders", rateProviderHandler.GetAllRateProviders)
+
+	mux.HandleFunc("GET /feeRules", feeRuleHandler.GetAllFeeRules)
+	mux.HandleFunc("GET /feeRule/{id}", feeRuleHandler.GetFeeRuleById)
+	mux.HandleFunc("POST /feeRules", feeRuleHandler.AddFeeRule)
+	mux.HandleFunc("PATCH /feeRule/{id}", feeRuleHandler.UpdateFeeRule)
+	mux.HandleFunc("DELETE /feeRule/{id}", feeRuleHandler.DeleteFeeRule)
 
 	mux.HandleFunc("GET /exchange", exchangeHandler.Exchange)
+	mux.HandleFunc("POST /exchange/batch", exchangeHandler.ExchangeBatch)
+
+	mux.HandleFunc("POST /quotes", quoteHandler.AddQuote)
+	mux.HandleFunc("POST /quotes/{id}/accept", quoteHandler.AcceptQuote)
+
+	job.NewConsistencyCheck(exchangeRatesStore, currencyStore, s.config.ConsistencyThreshold).
+		Start(s.config.ConsistencyCheckInterval)
+
+	var ingestedProviders []provider.RateProvider
+
+	for _, rateProvider := range rateProviders {
+		ingestedProviders = append(ingestedProviders, rateProvider)
+	}
+
+	job.NewRateIngestion(
+		ingestedProviders,
+		exchangeRatesStore,
+		currencyStore,
+		s.config.RateAggregation,
+		s.config.MaxRateDeviation,
+	).Start(s.config.RateProviderInterval)
+
+	if !s.config.MaxRateDeviation.IsPositive() {
+		slog.Warn("Rate deviation check is disabled, MAX_RATE_DEVIATION is 0")
+	}
+
+	if s.config.RateApprovalRequired && len(ingestedProviders) != 0 {
+		slog.Warn("Rate ingestion bypasses the rate change approval")
+	}
 
 	slog.Info("Starting server")
 
@@ -61,3 +146,18 @@ func (s *Server) Run() {
 		os.Exit(1)
 	}
 }
+
+func (s *Server) rateProviders() []*provider.ResilientProvider {
+	client := &http.Client{Timeout: s.config.RateProviderTimeout}
+
+	var providers []*provider.ResilientProvider
+
+	for _, rateProvider := range s.config.RateProviders {
+		providers = append(providers, provider.NewResilientProvider(
+			provider.New(rateProvider.Format, rateProvider.Name, rateProvider.URL, client),
+			s.config.RateProviderResilience,
+		))
+	}
+
+	return providers
+}
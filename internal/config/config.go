@@ -0,0 +1,322 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	// Currency codes missing from the ISO 4217 catalog are rejected
+	UnknownCurrencyCodesRejected bool
+
+	// Currencies tried in order when neither a direct nor an inverse exchange
+	// rate exists for the requested pair
+	PivotCurrencies []string
+
+	// How long an issued quote can be accepted
+	QuoteTTL time.Duration
+
+	// Percentage by which the product of rates around a cycle may deviate
+	// from 1 before the cycle is reported as inconsistent
+	ConsistencyThreshold decimal.Decimal
+	// How often the background consistency check runs, 0 disables it
+	ConsistencyCheckInterval time.Duration
+
+	// Max age of exchange rates and what to do with older ones
+	Staleness exchange.Staleness
+
+	// Percentage by which an update may change the current exchange rate
+	// without being forced, 0 disables the check
+	MaxRateDeviation decimal.Decimal
+
+	// Exchange rate changes have to be approved by a different user
+	RateApprovalRequired bool
+
+	// External sources exchange rates are pulled from periodically
+	RateProviders []RateProvider
+	// How often rates are pulled, 0 disables the ingestion
+	RateProviderInterval time.Duration
+	RateProviderTimeout  time.Duration
+	// Retries and circuit breaker of each rate provider
+	RateProviderResilience provider.Resilience
+	// How rates several providers quote for the same pair are combined
+	RateAggregation exchange.Aggregator
+}
+
+type RateProvider struct {
+	Name   string
+	Format provider.Format
+	URL    string
+}
+
+func Load() *Config {
+	return &Config{
+		UnknownCurrencyCodesRejected: loadBool("REJECT_UNKNOWN_CURRENCY_CODES", false),
+
+		PivotCurrencies: loadCurrencyCodes("PIVOT_CURRENCIES", []string{"USD"}),
+		QuoteTTL:        loadDuration("QUOTE_TTL", 30*time.Second),
+
+		ConsistencyThreshold:     loadDecimal("CONSISTENCY_THRESHOLD", decimal.NewFromInt(1)),
+		ConsistencyCheckInterval: loadNonNegativeDuration("CONSISTENCY_CHECK_INTERVAL", time.Hour),
+
+		Staleness: exchange.Staleness{
+			MaxAge:      loadNonNegativeDuration("RATE_MAX_AGE", 0),
+			PairMaxAges: loadPairDurations("RATE_MAX_AGE_PAIRS"),
+			Policy:      loadStaleRatePolicy("STALE_RATE_POLICY", exchange.StaleRatePolicyReject),
+		},
+
+		MaxRateDeviation: loadDecimal("MAX_RATE_DEVIATION", decimal.NewFromInt(10)),
+
+		RateApprovalRequired: loadBool("RATE_APPROVAL_REQUIRED", false),
+
+		RateProviders:        loadRateProviders("RATE_PROVIDERS"),
+		RateProviderInterval: loadNonNegativeDuration("RATE_PROVIDER_INTERVAL", time.Hour),
+		RateProviderTimeout:  loadDuration("RATE_PROVIDER_TIMEOUT", 10*time.Second),
+		RateProviderResilience: provider.Resilience{
+			MaxAttempts:      loadPositiveInt("RATE_PROVIDER_MAX_ATTEMPTS", 3),
+			Backoff:          loadNonNegativeDuration("RATE_PROVIDER_BACKOFF", time.Second),
+			MaxBackoff:       loadNonNegativeDuration("RATE_PROVIDER_MAX_BACKOFF", 30*time.Second),
+			FailureThreshold: loadPositiveInt("RATE_PROVIDER_FAILURE_THRESHOLD", 5),
+			Cooldown:         loadDuration("RATE_PROVIDER_COOLDOWN", 5*time.Minute),
+		},
+		RateAggregation: exchange.Aggregator{
+			Method:           loadAggregation("RATE_AGGREGATION", exchange.AggregationMedian),
+			Trim:             loadDecimal("RATE_AGGREGATION_TRIM", decimal.NewFromInt(10)),
+			OutlierThreshold: loadDecimal("RATE_OUTLIER_THRESHOLD", decimal.Zero),
+		},
+	}
+}
+
+func loadCurrencyCodes(key string, fallback []string) []string {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	var codes []string
+
+	for _, code := range strings.Split(value, ",") {
+		code = strings.TrimSpace(code)
+
+		if len(code) == 0 {
+			continue
+		}
+
+		if err := validator.ValidateCurrencyCode(code); err != nil {
+			slog.Error("Invalid currency code in configuration", "key", key, "error", err)
+			os.Exit(1)
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+func loadDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil || duration <= 0 {
+		slog.Error("Invalid duration in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return duration
+}
+
+// loadNonNegativeDuration accepts 0 as well, for settings where it disables a
+// job or check
+func loadNonNegativeDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil || duration < 0 {
+		slog.Error("Invalid duration in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return duration
+}
+
+func loadPositiveInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+
+	if err != nil || number < 1 {
+		slog.Error("Invalid positive integer in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return number
+}
+
+func loadDecimal(key string, fallback decimal.Decimal) decimal.Decimal {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	number, err := decimal.NewFromString(value)
+
+	if err != nil || number.IsNegative() {
+		slog.Error("Invalid number in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return number
+}
+
+// loadPairDurations parses a comma separated list of durations keyed by
+// currency code pairs, e.g. USDEUR=1h,EURGBP=30m
+func loadPairDurations(key string) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return durations
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if len(entry) == 0 {
+			continue
+		}
+
+		codePair, durationStr, found := strings.Cut(entry, "=")
+		duration, err := time.ParseDuration(durationStr)
+
+		if !found || len(codePair) != 6 || err != nil || duration < 0 {
+			slog.Error("Invalid currency pair duration in configuration", "key", key, "value", entry)
+			os.Exit(1)
+		}
+
+		if err := errors.Join(validator.ValidateCurrencyCode(codePair[0:3]), validator.ValidateCurrencyCode(codePair[3:6])); err != nil {
+			slog.Error("Invalid currency code in configuration", "key", key, "error", err)
+			os.Exit(1)
+		}
+
+		durations[codePair] = duration
+	}
+
+	return durations
+}
+
+func loadStaleRatePolicy(key string, fallback exchange.StaleRatePolicy) exchange.StaleRatePolicy {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	policy := exchange.StaleRatePolicy(value)
+
+	if !slices.Contains(exchange.StaleRatePolicies, policy) {
+		slog.Error("Invalid stale rate policy in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return policy
+}
+
+func loadAggregation(key string, fallback exchange.Aggregation) exchange.Aggregation {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	aggregation := exchange.Aggregation(value)
+
+	if !slices.Contains(exchange.Aggregations, aggregation) {
+		slog.Error("Invalid rate aggregation in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return aggregation
+}
+
+func loadBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return fallback
+	}
+
+	flag, err := strconv.ParseBool(value)
+
+	if err != nil {
+		slog.Error("Invalid boolean in configuration", "key", key, "value", value)
+		os.Exit(1)
+	}
+
+	return flag
+}
+
+// loadRateProviders parses a comma separated list of rate provider names and
+// URLs, optionally with the response format, e.g.
+// main=https://example.com/rates,ecb:ecb=https://example.com/eurofxref-daily.xml
+func loadRateProviders(key string) []RateProvider {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return nil
+	}
+
+	var providers []RateProvider
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if len(entry) == 0 {
+			continue
+		}
+
+		name, rawURL, found := strings.Cut(entry, "=")
+		name, format, hasFormat := strings.Cut(name, ":")
+		parsedURL, err := url.Parse(rawURL)
+
+		if !hasFormat {
+			format = string(provider.FormatJSON)
+		}
+
+		if !found || len(name) == 0 || err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") ||
+			!slices.Contains(provider.Formats, provider.Format(format)) {
+			slog.Error("Invalid rate provider in configuration", "key", key, "value", entry)
+			os.Exit(1)
+		}
+
+		providers = append(providers, RateProvider{Name: name, Format: provider.Format(format), URL: rawURL})
+	}
+
+	return providers
+}
@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+type Aggregation string
+
+const (
+	// The middle of the source rates
+	AggregationMedian Aggregation = "median"
+	// The mean of the source rates without the lowest and highest ones
+	AggregationTrimmedMean Aggregation = "trimmedMean"
+	// The rate of the source with the highest priority
+	AggregationPriority Aggregation = "priority"
+)
+
+var Aggregations = []Aggregation{AggregationMedian, AggregationTrimmedMean, AggregationPriority}
+
+// SourceRate is a rate of a currency pair quoted by one of several sources. A
+// lower priority value comes first.
+type SourceRate struct {
+	Source   string
+	Rate     decimal.Decimal
+	Priority int
+	// Whether the rate was rejected as an outlier by the aggregation
+	Rejected bool
+}
+
+// Aggregator combines the rates several sources quote for the same pair
+type Aggregator struct {
+	Method Aggregation
+	// Percentage of the source rates dropped from each end for the trimmed
+	// mean
+	Trim decimal.Decimal
+	// Percentage by which a source rate may deviate from the median of all
+	// source rates before it's rejected as an outlier, 0 disables the check
+	OutlierThreshold decimal.Decimal
+}
+
+// Aggregate returns the aggregated rate along with the source rates marked as
+// rejected or not. It fails when there are no source rates or all of them are
+// outliers.
+func (a Aggregator) Aggregate(rates []SourceRate) (decimal.Decimal, []SourceRate, bool) {
+	if len(rates) == 0 {
+		return decimal.Zero, nil, false
+	}
+
+	marked := make([]SourceRate, 0, len(rates))
+	var accepted []SourceRate
+
+	consensus := median(rates)
+
+	for _, rate := range rates {
+		rate.Rejected = a.OutlierThreshold.IsPositive() &&
+			Deviation(consensus, rate.Rate).GreaterThan(a.OutlierThreshold)
+
+		if !rate.Rejected {
+			accepted = append(accepted, rate)
+		}
+
+		marked = append(marked, rate)
+	}
+
+	if len(accepted) == 0 {
+		return decimal.Zero, marked, false
+	}
+
+	switch a.Method {
+	case AggregationTrimmedMean:
+		return trimmedMean(accepted, a.Trim), marked, true
+	case AggregationPriority:
+		return Top(accepted).Rate, marked, true
+	default:
+		return median(accepted), marked, true
+	}
+}
+
+// Top returns the source rate with the highest priority
+func Top(rates []SourceRate) SourceRate {
+	return slices.MinFunc(rates, func(x, y SourceRate) int {
+		return x.Priority - y.Priority
+	})
+}
+
+func sortedRates(rates []SourceRate) []decimal.Decimal {
+	sorted := make([]decimal.Decimal, 0, len(rates))
+
+	for _, rate := range rates {
+		sorted = append(sorted, rate.Rate)
+	}
+
+	slices.SortFunc(sorted, func(x, y decimal.Decimal) int {
+		return x.Cmp(y)
+	})
+
+	return sorted
+}
+
+func median(rates []SourceRate) decimal.Decimal {
+	sorted := sortedRates(rates)
+	middle := len(sorted) / 2
+
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+
+	return sorted[middle-1].Add(sorted[middle]).DivRound(decimal.NewFromInt(2), RatePrecision)
+}
+
+func trimmedMean(rates []SourceRate, trim decimal.Decimal) decimal.Decimal {
+	sorted := sortedRates(rates)
+	dropped := int(trim.Shift(-2).Mul(decimal.NewFromInt(int64(len(sorted)))).IntPart())
+
+	if 2*dropped >= len(sorted) {
+		dropped = (len(sorted) - 1) / 2
+	}
+
+	kept := sorted[dropped : len(sorted)-dropped]
+
+	return decimal.Sum(kept[0], kept[1:]...).DivRound(decimal.NewFromInt(int64(len(kept))), RatePrecision)
+}
+
+// SourceSpread returns by how many percent the highest and lowest source rates
+// differ relative to the aggregated rate
+func SourceSpread(rate decimal.Decimal, rates []SourceRate) decimal.Decimal {
+	if len(rates) == 0 || !rate.IsPositive() {
+		return decimal.Zero
+	}
+
+	sorted := sortedRates(rates)
+
+	return sorted[len(sorted)-1].Sub(sorted[0]).Shift(2).DivRound(rate, RatePrecision)
+}
@@ -0,0 +1,146 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func sourceRates(rates ...string) []SourceRate {
+	var sourceRates []SourceRate
+
+	for i, rate := range rates {
+		sourceRates = append(sourceRates, SourceRate{
+			Source:   string(rune('a' + i)),
+			Rate:     decimal.RequireFromString(rate),
+			Priority: i,
+		})
+	}
+
+	return sourceRates
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name       string
+		aggregator Aggregator
+		rates      []SourceRate
+		want       string
+		rejected   []bool
+		ok         bool
+	}{
+		{
+			name:       "no rates",
+			aggregator: Aggregator{Method: AggregationMedian},
+			ok:         false,
+		},
+		{
+			name:       "single rate",
+			aggregator: Aggregator{Method: AggregationMedian},
+			rates:      sourceRates("0.92"),
+			want:       "0.92",
+			rejected:   []bool{false},
+			ok:         true,
+		},
+		{
+			name:       "median of odd count",
+			aggregator: Aggregator{Method: AggregationMedian},
+			rates:      sourceRates("0.93", "0.91", "0.92"),
+			want:       "0.92",
+			rejected:   []bool{false, false, false},
+			ok:         true,
+		},
+		{
+			name:       "median of even count",
+			aggregator: Aggregator{Method: AggregationMedian},
+			rates:      sourceRates("0.90", "0.94"),
+			want:       "0.92",
+			rejected:   []bool{false, false},
+			ok:         true,
+		},
+		{
+			name:       "trimmed mean",
+			aggregator: Aggregator{Method: AggregationTrimmedMean, Trim: decimal.NewFromInt(20)},
+			rates:      sourceRates("0.80", "0.91", "0.92", "0.93", "1.20"),
+			want:       "0.92",
+			rejected:   []bool{false, false, false, false, false},
+			ok:         true,
+		},
+		{
+			name:       "trimmed mean keeps the middle",
+			aggregator: Aggregator{Method: AggregationTrimmedMean, Trim: decimal.NewFromInt(50)},
+			rates:      sourceRates("0.90", "0.94"),
+			want:       "0.92",
+			rejected:   []bool{false, false},
+			ok:         true,
+		},
+		{
+			name:       "priority",
+			aggregator: Aggregator{Method: AggregationPriority},
+			rates:      sourceRates("0.93", "0.91", "0.92"),
+			want:       "0.93",
+			rejected:   []bool{false, false, false},
+			ok:         true,
+		},
+		{
+			name:       "outlier rejected",
+			aggregator: Aggregator{Method: AggregationMedian, OutlierThreshold: decimal.NewFromInt(5)},
+			rates:      sourceRates("0.91", "9.2", "0.93"),
+			want:       "0.92",
+			rejected:   []bool{false, true, false},
+			ok:         true,
+		},
+		{
+			name:       "priority falls back when the top source is an outlier",
+			aggregator: Aggregator{Method: AggregationPriority, OutlierThreshold: decimal.NewFromInt(5)},
+			rates:      sourceRates("9.2", "0.91", "0.93"),
+			want:       "0.91",
+			rejected:   []bool{true, false, false},
+			ok:         true,
+		},
+		{
+			name:       "no consensus",
+			aggregator: Aggregator{Method: AggregationMedian, OutlierThreshold: decimal.NewFromInt(5)},
+			rates:      sourceRates("0.5", "1"),
+			want:       "0",
+			rejected:   []bool{true, true},
+			ok:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, marked, ok := tt.aggregator.Aggregate(tt.rates)
+
+			if ok != tt.ok {
+				t.Fatalf("Aggregate() ok = %t, want %t", ok, tt.ok)
+			}
+			if len(tt.want) != 0 && !rate.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Aggregate() = %s, want %s", rate, tt.want)
+			}
+			if len(marked) != len(tt.rejected) {
+				t.Fatalf("Aggregate() marked %d rates, want %d", len(marked), len(tt.rejected))
+			}
+
+			for i, sourceRate := range marked {
+				if sourceRate.Source != tt.rates[i].Source {
+					t.Errorf("Marked rate %d is from %s, want the order kept", i, sourceRate.Source)
+				}
+				if sourceRate.Rejected != tt.rejected[i] {
+					t.Errorf("Rate %s rejected = %t, want %t", sourceRate.Rate, sourceRate.Rejected, tt.rejected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSourceSpread(t *testing.T) {
+	got := SourceSpread(decimal.RequireFromString("0.92"), sourceRates("0.91", "0.93", "0.92"))
+
+	if want := decimal.RequireFromString("2.1739130434782609"); !got.Equal(want) {
+		t.Errorf("SourceSpread() = %s, want %s", got, want)
+	}
+	if got := SourceSpread(decimal.Zero, sourceRates("0.91")); !got.IsZero() {
+		t.Errorf("SourceSpread() of a zero rate = %s, want 0", got)
+	}
+}
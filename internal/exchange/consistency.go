@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+// Inconsistency is a cycle of exchange rates whose product deviates from 1,
+// i.e. converting around the cycle doesn't return the initial amount.
+type Inconsistency struct {
+	Cycle Path
+	// Product of the mid rates around the cycle
+	Product decimal.Decimal
+	// Deviation of the product from 1 in percent
+	Deviation decimal.Decimal
+}
+
+// Cycles returns every simple cycle of at most MaxPathLength legs that uses
+// each exchange rate at most once. A cycle is returned once, starting from its
+// lowest currency id, in a single direction.
+func (g *Graph) Cycles() []Path {
+	var nodes []int64
+	for node := range g.edges {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+
+	var cycles []Path
+
+	for _, start := range nodes {
+		visited := map[int64]bool{start: true}
+		used := map[int64]bool{}
+		var current Path
+
+		var walk func(node int64)
+		walk = func(node int64) {
+			if len(current) == MaxPathLength {
+				return
+			}
+
+			for _, leg := range g.edges[node] {
+				if used[leg.ExchangeRate.Id] || leg.To() < start {
+					continue
+				}
+
+				if leg.To() == start {
+					// Walking the cycle backwards gives the same rates, keep
+					// only the direction with the lower first rate id
+					if len(current) > 0 && current[0].ExchangeRate.Id < leg.ExchangeRate.Id {
+						cycles = append(cycles, append(append(Path(nil), current...), leg))
+					}
+					continue
+				}
+
+				if visited[leg.To()] {
+					continue
+				}
+
+				visited[leg.To()] = true
+				used[leg.ExchangeRate.Id] = true
+				current = append(current, leg)
+
+				walk(leg.To())
+
+				current = current[:len(current)-1]
+				used[leg.ExchangeRate.Id] = false
+				visited[leg.To()] = false
+			}
+		}
+
+		walk(start)
+	}
+
+	return cycles
+}
+
+// FindInconsistencies returns the cycles of the graph whose rate product
+// deviates from 1 by more than the threshold, given in percent.
+func FindInconsistencies(g *Graph, threshold decimal.Decimal) []Inconsistency {
+	var inconsistencies []Inconsistency
+
+	for _, cycle := range g.Cycles() {
+		numerator, denominator := cycle.WithSide("").fraction()
+
+		product := numerator.DivRound(denominator, RatePrecision)
+		deviation := Deviation(denominator, numerator)
+
+		if deviation.GreaterThan(threshold) {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Cycle:     cycle,
+				Product:   product,
+				Deviation: deviation,
+			})
+		}
+	}
+
+	return inconsistencies
+}
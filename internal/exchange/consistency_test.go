@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+func exchangeRate(id int64, baseId int64, targetId int64, rate string) model.ExchangeRate {
+	return model.ExchangeRate{
+		Id:               id,
+		BaseCurrencyId:   baseId,
+		TargetCurrencyId: targetId,
+		Rate:             decimal.RequireFromString(rate),
+	}
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name          string
+		exchangeRates []model.ExchangeRate
+		want          int
+	}{
+		{"no rates", nil, 0},
+		{"single rate", []model.ExchangeRate{exchangeRate(1, 1, 2, "2")}, 0},
+		{"chain", []model.ExchangeRate{
+			exchangeRate(1, 1, 2, "2"),
+			exchangeRate(2, 2, 3, "3"),
+		}, 0},
+		{"rate and its reverse", []model.ExchangeRate{
+			exchangeRate(1, 1, 2, "2"),
+			exchangeRate(2, 2, 1, "0.5"),
+		}, 1},
+		{"triangle", []model.ExchangeRate{
+			exchangeRate(1, 1, 2, "2"),
+			exchangeRate(2, 2, 3, "3"),
+			exchangeRate(3, 1, 3, "6"),
+		}, 1},
+		{"triangle with a dangling rate", []model.ExchangeRate{
+			exchangeRate(1, 1, 2, "2"),
+			exchangeRate(2, 2, 3, "3"),
+			exchangeRate(3, 1, 3, "6"),
+			exchangeRate(4, 3, 4, "10"),
+		}, 1},
+		// 1-2-3, 1-3-4 and 1-2-3-4
+		{"two triangles sharing a rate", []model.ExchangeRate{
+			exchangeRate(1, 1, 2, "2"),
+			exchangeRate(2, 2, 3, "3"),
+			exchangeRate(3, 1, 3, "6"),
+			exchangeRate(4, 3, 4, "10"),
+			exchangeRate(5, 1, 4, "60"),
+		}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles := NewGraph(tt.exchangeRates).Cycles()
+
+			if len(cycles) != tt.want {
+				t.Fatalf("Cycles() returned %d cycles, want %d", len(cycles), tt.want)
+			}
+
+			for _, cycle := range cycles {
+				if cycle[0].From() != cycle[len(cycle)-1].To() {
+					t.Errorf("Cycle %v doesn't end where it starts", cycle)
+				}
+			}
+		})
+	}
+}
+
+func TestFindInconsistencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      string
+		threshold string
+		want      string
+	}{
+		{"consistent", "6", "1", ""},
+		{"within threshold", "6.05", "1", ""},
+		{"above threshold", "6.6", "1", "9.0909090909090909"},
+		{"below one", "5.4", "1", "11.1111111111111111"},
+		{"threshold relative to the inverse rate", "6.06", "1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := NewGraph([]model.ExchangeRate{
+				exchangeRate(1, 1, 2, "2"),
+				exchangeRate(2, 2, 3, "3"),
+				exchangeRate(3, 1, 3, tt.rate),
+			})
+
+			inconsistencies := FindInconsistencies(graph, decimal.RequireFromString(tt.threshold))
+
+			if len(tt.want) == 0 {
+				if len(inconsistencies) != 0 {
+					t.Errorf("FindInconsistencies() = %v, want none", inconsistencies)
+				}
+				return
+			}
+
+			if len(inconsistencies) != 1 {
+				t.Fatalf("FindInconsistencies() returned %d inconsistencies, want 1", len(inconsistencies))
+			}
+			if deviation := inconsistencies[0].Deviation; !deviation.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Deviation = %s, want %s", deviation, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathCodes(t *testing.T) {
+	currencies := map[int64]*model.Currency{
+		1: {Id: 1, Code: "USD"},
+		2: {Id: 2, Code: "EUR"},
+		3: {Id: 3, Code: "GBP"},
+	}
+
+	path := Path{
+		{ExchangeRate: exchangeRate(1, 1, 2, "0.92")},
+		{ExchangeRate: exchangeRate(2, 3, 2, "1.17"), Inverse: true},
+	}
+
+	codes, err := path.Codes(func(id int64) (*model.Currency, error) {
+		return currencies[id], nil
+	})
+
+	if err != nil {
+		t.Fatalf("Codes() failed: %s", err)
+	}
+	if len(codes) != 3 || codes[0] != "USD" || codes[1] != "EUR" || codes[2] != "GBP" {
+		t.Errorf("Codes() = %v, want [USD EUR GBP]", codes)
+	}
+}
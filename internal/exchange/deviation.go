@@ -0,0 +1,9 @@
+package exchange
+
+import "github.com/shopspring/decimal"
+
+// Deviation returns by how many percent the rate differs from the reference
+// rate
+func Deviation(reference decimal.Decimal, rate decimal.Decimal) decimal.Decimal {
+	return rate.Sub(reference).Abs().Shift(2).DivRound(reference, RatePrecision)
+}
@@ -0,0 +1,36 @@
+package exchange
+
+import "github.com/shopspring/decimal"
+
+// Step describes how a single leg of a path contributes to a conversion.
+type Step struct {
+	Leg Leg
+	// Stored rate the leg converts at
+	Quote decimal.Decimal
+	// Rate of the leg in its direction
+	Rate decimal.Decimal
+	// Rate of the path up to and including the leg
+	PathRate decimal.Decimal
+	// Unrounded amount after the leg, to RatePrecision decimal places
+	Amount decimal.Decimal
+}
+
+// Steps returns the intermediate rates and amounts of converting the amount
+// along the path. They are informational, Convert rounds only once at the end.
+func (p Path) Steps(amount decimal.Decimal) []Step {
+	steps := make([]Step, len(p))
+
+	for i, leg := range p {
+		numerator, denominator := p[:i+1].fraction()
+
+		steps[i] = Step{
+			Leg:      leg,
+			Quote:    leg.quote(),
+			Rate:     leg.Rate(),
+			PathRate: numerator.DivRound(denominator, RatePrecision),
+			Amount:   amount.Mul(numerator).DivRound(denominator, RatePrecision),
+		}
+	}
+
+	return steps
+}
@@ -0,0 +1,154 @@
+package exchange
+
+import (
+	"errors"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// Fee returns the fee charged on the gross converted amount, rounded to the
+// given number of decimal places. The fee never exceeds the amount itself.
+func Fee(feeRule model.FeeRule, gross decimal.Decimal, places int32, mode model.RoundingMode) decimal.Decimal {
+	return decimal.Min(tierFee(feeTier(feeRule, gross), gross, places, mode), gross)
+}
+
+func tierFee(tier model.FeeTier, gross decimal.Decimal, places int32, mode model.RoundingMode) decimal.Decimal {
+	fee := decimal.Zero
+
+	if tier.Amount.Valid {
+		fee = fee.Add(tier.Amount.Decimal)
+	}
+	if tier.Percentage.Valid {
+		fee = fee.Add(gross.Mul(tier.Percentage.Decimal).Shift(-2))
+	}
+
+	return Round(fee, places, mode)
+}
+
+// feeTier returns flat and percentage rules as a single tier. Amounts above
+// the last tier are charged by it even when it's bounded.
+func feeTier(feeRule model.FeeRule, gross decimal.Decimal) model.FeeTier {
+	switch feeRule.Type {
+	case model.FeeTypeFlat:
+		return model.FeeTier{Amount: feeRule.Amount}
+	case model.FeeTypePercentage:
+		return model.FeeTier{Percentage: feeRule.Percentage}
+	}
+
+	for _, tier := range feeRule.Tiers {
+		if !tier.UpTo.Valid || gross.LessThanOrEqual(tier.UpTo.Decimal) {
+			return tier
+		}
+	}
+
+	if len(feeRule.Tiers) == 0 {
+		return model.FeeTier{}
+	}
+
+	return feeRule.Tiers[len(feeRule.Tiers)-1]
+}
+
+// GrossAmount returns the smallest gross converted amount, with the given
+// number of decimal places, that leaves at least the net amount after the fee.
+func GrossAmount(
+	feeRule model.FeeRule,
+	net decimal.Decimal,
+	places int32,
+	mode model.RoundingMode,
+) (decimal.Decimal, error) {
+	if net.IsZero() {
+		return net, nil
+	}
+
+	unit := decimal.New(1, -places)
+
+	var gross decimal.Decimal
+	found := false
+
+	// Net amount grows with the gross one within a tier, so the smallest gross
+	// amount of each tier is found separately
+	lower := decimal.NullDecimal{}
+
+	for _, tier := range feeTiers(feeRule) {
+		candidate, ok := grossAmountInTier(tier, net, places, mode)
+
+		if ok && lower.Valid {
+			candidate = decimal.Max(candidate, lower.Decimal.Add(unit))
+		}
+
+		inTier := !tier.UpTo.Valid || candidate.LessThanOrEqual(tier.UpTo.Decimal)
+
+		if ok && inTier && candidate.Sub(Fee(feeRule, candidate, places, mode)).GreaterThanOrEqual(net) {
+			if !found || candidate.LessThan(gross) {
+				gross = candidate
+				found = true
+			}
+		}
+
+		lower = tier.UpTo
+	}
+
+	if !found {
+		return decimal.Zero, errors.New("Net amount cannot be reached with the applicable fee rule")
+	}
+
+	return gross, nil
+}
+
+// grossAmountInTier solves gross - fee(gross) = net for the tier and corrects
+// the result for the rounding of the fee
+func grossAmountInTier(
+	tier model.FeeTier,
+	net decimal.Decimal,
+	places int32,
+	mode model.RoundingMode,
+) (decimal.Decimal, bool) {
+	unit := decimal.New(1, -places)
+
+	numerator := net
+	denominator := decimal.NewFromInt(1)
+
+	if tier.Amount.Valid {
+		numerator = numerator.Add(tier.Amount.Decimal)
+	}
+	if tier.Percentage.Valid {
+		denominator = denominator.Sub(tier.Percentage.Decimal.Shift(-2))
+	}
+
+	if !denominator.IsPositive() {
+		return decimal.Zero, false
+	}
+
+	gross := roundQuotient(numerator, denominator, places, model.RoundingModeCeiling)
+
+	for gross.Sub(unit).Sub(tierFee(tier, gross.Sub(unit), places, mode)).GreaterThanOrEqual(net) {
+		gross = gross.Sub(unit)
+	}
+	for gross.Sub(tierFee(tier, gross, places, mode)).LessThan(net) {
+		gross = gross.Add(unit)
+	}
+
+	return gross, true
+}
+
+// feeTiers returns the tiers of the fee rule, the last one unbounded as it
+// charges the amounts above its bound as well
+func feeTiers(feeRule model.FeeRule) []model.FeeTier {
+	switch feeRule.Type {
+	case model.FeeTypeFlat:
+		return []model.FeeTier{{Amount: feeRule.Amount}}
+	case model.FeeTypePercentage:
+		return []model.FeeTier{{Percentage: feeRule.Percentage}}
+	}
+
+	tiers := append([]model.FeeTier(nil), feeRule.Tiers...)
+
+	if len(tiers) == 0 {
+		return []model.FeeTier{{}}
+	}
+
+	tiers[len(tiers)-1].UpTo = decimal.NullDecimal{}
+
+	return tiers
+}
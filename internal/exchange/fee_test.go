@@ -0,0 +1,121 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+func nullDecimal(value string) decimal.NullDecimal {
+	return decimal.NewNullDecimal(decimal.RequireFromString(value))
+}
+
+var (
+	flatFee = model.FeeRule{
+		Type:   model.FeeTypeFlat,
+		Amount: nullDecimal("1.5"),
+	}
+	percentageFee = model.FeeRule{
+		Type:       model.FeeTypePercentage,
+		Percentage: nullDecimal("2"),
+	}
+	tieredFee = model.FeeRule{
+		Type: model.FeeTypeTiered,
+		Tiers: []model.FeeTier{
+			{UpTo: nullDecimal("100"), Amount: nullDecimal("1")},
+			{UpTo: nullDecimal("1000"), Percentage: nullDecimal("1")},
+			{Amount: nullDecimal("5"), Percentage: nullDecimal("0.5")},
+		},
+	}
+	boundedTieredFee = model.FeeRule{
+		Type: model.FeeTypeTiered,
+		Tiers: []model.FeeTier{
+			{UpTo: nullDecimal("100"), Amount: nullDecimal("1")},
+			{UpTo: nullDecimal("1000"), Percentage: nullDecimal("1")},
+		},
+	}
+)
+
+func TestFee(t *testing.T) {
+	tests := []struct {
+		name    string
+		feeRule model.FeeRule
+		gross   string
+		places  int32
+		mode    model.RoundingMode
+		want    string
+	}{
+		{"flat", flatFee, "50", 2, model.RoundingModeHalfUp, "1.5"},
+		{"flat capped by amount", flatFee, "1", 2, model.RoundingModeHalfUp, "1"},
+		{"flat without minor units", flatFee, "50", 0, model.RoundingModeHalfUp, "2"},
+		{"percentage", percentageFee, "123.45", 2, model.RoundingModeHalfUp, "2.47"},
+		{"percentage floor", percentageFee, "123.45", 2, model.RoundingModeFloor, "2.46"},
+		{"first tier", tieredFee, "80", 2, model.RoundingModeHalfUp, "1"},
+		{"first tier bound inclusive", tieredFee, "100", 2, model.RoundingModeHalfUp, "1"},
+		{"second tier", tieredFee, "100.01", 2, model.RoundingModeHalfUp, "1"},
+		{"second tier percentage", tieredFee, "500", 2, model.RoundingModeHalfUp, "5"},
+		{"unbounded tier", tieredFee, "2000", 2, model.RoundingModeHalfUp, "15"},
+		{"above bounded last tier", boundedTieredFee, "2000", 2, model.RoundingModeHalfUp, "20"},
+		{"zero amount", percentageFee, "0", 2, model.RoundingModeHalfUp, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fee(tt.feeRule, decimal.RequireFromString(tt.gross), tt.places, tt.mode)
+
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Fee(%s) = %s, want %s", tt.gross, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrossAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		feeRule model.FeeRule
+		net     string
+		places  int32
+		want    string
+	}{
+		{"flat", flatFee, "100", 2, "101.5"},
+		{"percentage", percentageFee, "98", 2, "100"},
+		{"percentage rounded fee", percentageFee, "100", 2, "102.04"},
+		{"first tier", tieredFee, "50", 2, "51"},
+		{"across tier bound", tieredFee, "99.5", 2, "100.51"},
+		{"unbounded tier", tieredFee, "1985", 2, "2000"},
+		{"above bounded last tier", boundedTieredFee, "1980", 2, "2000"},
+		{"zero", flatFee, "0", 2, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := decimal.RequireFromString(tt.net)
+
+			got, err := GrossAmount(tt.feeRule, net, tt.places, model.RoundingModeHalfUp)
+
+			if err != nil {
+				t.Fatalf("GrossAmount(%s) failed: %s", tt.net, err)
+			}
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("GrossAmount(%s) = %s, want %s", tt.net, got, tt.want)
+			}
+
+			unit := decimal.New(1, -tt.places)
+			below := got.Sub(unit)
+
+			if got.IsPositive() && below.Sub(Fee(tt.feeRule, below, tt.places, model.RoundingModeHalfUp)).GreaterThanOrEqual(net) {
+				t.Errorf("GrossAmount(%s) = %s isn't the smallest, %s leaves the net amount as well", tt.net, got, below)
+			}
+		})
+	}
+}
+
+func TestGrossAmountUnreachable(t *testing.T) {
+	feeRule := model.FeeRule{Type: model.FeeTypePercentage, Percentage: nullDecimal("100")}
+
+	if _, err := GrossAmount(feeRule, decimal.NewFromInt(10), 2, model.RoundingModeHalfUp); err == nil {
+		t.Error("GrossAmount with a 100% fee succeeded")
+	}
+}
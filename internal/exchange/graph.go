@@ -0,0 +1,129 @@
+package exchange
+
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// MaxPathLength limits the number of legs a conversion path may contain when
+// searching for the best rate, otherwise every simple path in the graph would
+// have to be explored.
+const MaxPathLength = 4
+
+// Graph treats every exchange rate as an edge between two currencies that can
+// be walked in both directions.
+type Graph struct {
+	edges map[int64][]Leg
+}
+
+func NewGraph(exchangeRates []model.ExchangeRate) *Graph {
+	g := &Graph{edges: make(map[int64][]Leg)}
+
+	for _, exchangeRate := range exchangeRates {
+		forward := Leg{ExchangeRate: exchangeRate}
+		inverse := Leg{ExchangeRate: exchangeRate, Inverse: true}
+
+		g.edges[forward.From()] = append(g.edges[forward.From()], forward)
+		g.edges[inverse.From()] = append(g.edges[inverse.From()], inverse)
+	}
+
+	return g
+}
+
+// ShortestPath returns the path with the fewest legs between two currencies.
+func (g *Graph) ShortestPath(from int64, to int64) (Path, bool) {
+	if from == to {
+		return nil, false
+	}
+
+	previous := map[int64]Leg{}
+	visited := map[int64]bool{from: true}
+	queue := []int64{from}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, leg := range g.edges[current] {
+			if visited[leg.To()] {
+				continue
+			}
+			visited[leg.To()] = true
+			previous[leg.To()] = leg
+
+			if leg.To() == to {
+				return buildPath(previous, from, to), true
+			}
+
+			queue = append(queue, leg.To())
+		}
+	}
+
+	return nil, false
+}
+
+// BestRatePath returns the path with the highest resulting rate between two
+// currencies among all simple paths of at most MaxPathLength legs.
+func (g *Graph) BestRatePath(from int64, to int64) (Path, bool) {
+	if from == to {
+		return nil, false
+	}
+
+	var best Path
+	var bestNumerator, bestDenominator decimal.Decimal
+
+	visited := map[int64]bool{from: true}
+	var current Path
+
+	// The rate is carried as a fraction so that paths are compared exactly
+	var walk func(node int64, numerator decimal.Decimal, denominator decimal.Decimal)
+	walk = func(node int64, numerator decimal.Decimal, denominator decimal.Decimal) {
+		if len(current) == MaxPathLength {
+			return
+		}
+
+		for _, leg := range g.edges[node] {
+			if visited[leg.To()] {
+				continue
+			}
+
+			current = append(current, leg)
+			legNumerator, legDenominator := numerator, denominator
+
+			if leg.Inverse {
+				legDenominator = legDenominator.Mul(leg.quote())
+			} else {
+				legNumerator = legNumerator.Mul(leg.quote())
+			}
+
+			if leg.To() == to {
+				if best == nil || legNumerator.Mul(bestDenominator).GreaterThan(bestNumerator.Mul(legDenominator)) {
+					best = append(Path(nil), current...)
+					bestNumerator, bestDenominator = legNumerator, legDenominator
+				}
+			} else {
+				visited[leg.To()] = true
+				walk(leg.To(), legNumerator, legDenominator)
+				visited[leg.To()] = false
+			}
+
+			current = current[:len(current)-1]
+		}
+	}
+
+	walk(from, decimal.NewFromInt(1), decimal.NewFromInt(1))
+
+	return best, best != nil
+}
+
+func buildPath(previous map[int64]Leg, from int64, to int64) Path {
+	var path Path
+
+	for node := to; node != from; {
+		leg := previous[node]
+		path = append(Path{leg}, path...)
+		node = leg.From()
+	}
+
+	return path
+}
@@ -0,0 +1,180 @@
+package exchange
+
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// RatePrecision is the number of decimal places derived rates are rounded to.
+// Converted amounts are calculated from the exact stored rates instead.
+const RatePrecision = 16
+
+const (
+	QuoteMid = "mid"
+	QuoteBid = "bid"
+	QuoteAsk = "ask"
+)
+
+// Leg is a single conversion step backed by one row of Exchange_rates. An
+// inverse leg walks the row from target to base currency.
+//
+// Without a side the leg converts at the mid rate. Selling converts at the
+// bid of a forward leg and the ask of an inverse one, buying the other way
+// around, so the customer always gets the worse side of the spread.
+type Leg struct {
+	ExchangeRate model.ExchangeRate
+	Inverse      bool
+	Side         model.Side
+}
+
+func (l Leg) From() int64 {
+	if l.Inverse {
+		return l.ExchangeRate.TargetCurrencyId
+	}
+	return l.ExchangeRate.BaseCurrencyId
+}
+
+func (l Leg) To() int64 {
+	if l.Inverse {
+		return l.ExchangeRate.BaseCurrencyId
+	}
+	return l.ExchangeRate.TargetCurrencyId
+}
+
+func (l Leg) Rate() decimal.Decimal {
+	if l.Inverse {
+		return decimal.NewFromInt(1).DivRound(l.quote(), RatePrecision)
+	}
+	return l.quote()
+}
+
+// Quote returns which of the stored rates the leg converts at, one of
+// QuoteMid, QuoteBid or QuoteAsk
+func (l Leg) Quote() string {
+	switch {
+	case l.Side == model.SideSell && !l.Inverse, l.Side == model.SideBuy && l.Inverse:
+		return QuoteBid
+	case l.Side == model.SideBuy && !l.Inverse, l.Side == model.SideSell && l.Inverse:
+		return QuoteAsk
+	default:
+		return QuoteMid
+	}
+}
+
+// quote returns the stored rate the leg converts at
+func (l Leg) quote() decimal.Decimal {
+	switch l.Quote() {
+	case QuoteBid:
+		return Bid(l.ExchangeRate)
+	case QuoteAsk:
+		return Ask(l.ExchangeRate)
+	default:
+		return l.ExchangeRate.Rate
+	}
+}
+
+type Path []Leg
+
+func (p Path) WithSide(side model.Side) Path {
+	path := make(Path, len(p))
+
+	for i, leg := range p {
+		leg.Side = side
+		path[i] = leg
+	}
+
+	return path
+}
+
+// Spread returns the difference between the buying and selling rates of the
+// path.
+func (p Path) Spread() decimal.Decimal {
+	return p.WithSide(model.SideBuy).Rate().Sub(p.WithSide(model.SideSell).Rate())
+}
+
+func (p Path) Rate() decimal.Decimal {
+	numerator, denominator := p.fraction()
+	return numerator.DivRound(denominator, RatePrecision)
+}
+
+// Convert applies the path to the amount and rounds the result to the given
+// number of decimal places. The only division happens right before rounding,
+// so inverse legs don't accumulate any error.
+func (p Path) Convert(amount decimal.Decimal, places int32, mode model.RoundingMode) decimal.Decimal {
+	numerator, denominator := p.fraction()
+	return roundQuotient(amount.Mul(numerator), denominator, places, mode)
+}
+
+// SourceAmount returns the smallest amount in the source currency, with the
+// given number of decimal places, that converts to at least the target amount.
+func (p Path) SourceAmount(
+	target decimal.Decimal,
+	sourcePlaces int32,
+	targetPlaces int32,
+	mode model.RoundingMode,
+) decimal.Decimal {
+	numerator, denominator := p.fraction()
+
+	sourceUnit := decimal.New(1, -sourcePlaces)
+	targetUnit := decimal.New(1, -targetPlaces)
+
+	// The exact conversion of high is at least the target, so it rounds to at
+	// least the target in any mode, while no amount below low can get there
+	high := roundQuotient(target.Mul(denominator), numerator, sourcePlaces, model.RoundingModeCeiling)
+	low := roundQuotient(target.Sub(targetUnit).Mul(denominator), numerator, sourcePlaces, model.RoundingModeFloor)
+	low = decimal.Max(low, decimal.Zero)
+
+	for low.LessThan(high) {
+		steps := high.Sub(low).Div(sourceUnit)
+		middle := low.Add(steps.Div(decimal.NewFromInt(2)).Floor().Mul(sourceUnit))
+
+		if p.Convert(middle, targetPlaces, mode).GreaterThanOrEqual(target) {
+			high = middle
+		} else {
+			low = middle.Add(sourceUnit)
+		}
+	}
+
+	return high
+}
+
+// Codes returns the codes of the currencies along the path, from the source to
+// the target currency
+func (p Path) Codes(findCurrencyById func(id int64) (*model.Currency, error)) ([]string, error) {
+	var codes []string
+
+	for i, leg := range p {
+		if i == 0 {
+			currency, err := findCurrencyById(leg.From())
+			if err != nil {
+				return nil, err
+			}
+			codes = append(codes, currency.Code)
+		}
+
+		currency, err := findCurrencyById(leg.To())
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, currency.Code)
+	}
+
+	return codes, nil
+}
+
+// fraction returns the path rate as the product of forward rates over the
+// product of inverse rates.
+func (p Path) fraction() (decimal.Decimal, decimal.Decimal) {
+	numerator := decimal.NewFromInt(1)
+	denominator := decimal.NewFromInt(1)
+
+	for _, leg := range p {
+		if leg.Inverse {
+			denominator = denominator.Mul(leg.quote())
+		} else {
+			numerator = numerator.Mul(leg.quote())
+		}
+	}
+
+	return numerator, denominator
+}
@@ -0,0 +1,56 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+func leg(rate string, inverse bool) Leg {
+	return Leg{
+		ExchangeRate: model.ExchangeRate{Rate: decimal.RequireFromString(rate)},
+		Inverse:      inverse,
+	}
+}
+
+func TestSourceAmount(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         Path
+		target       string
+		sourcePlaces int32
+		targetPlaces int32
+		mode         model.RoundingMode
+		want         string
+	}{
+		{"forward", Path{leg("0.92", false)}, "92", 2, 2, model.RoundingModeHalfUp, "100"},
+		{"forward floor", Path{leg("0.92", false)}, "91.5", 2, 2, model.RoundingModeFloor, "99.46"},
+		{"inverse", Path{leg("0.92", true)}, "100", 2, 2, model.RoundingModeHalfUp, "92"},
+		{"to currency without minor units", Path{leg("149.5", false)}, "1000", 2, 0, model.RoundingModeHalfUp, "6.69"},
+		{"from currency without minor units", Path{leg("149.5", true)}, "10", 0, 2, model.RoundingModeHalfUp, "1495"},
+		{"two legs", Path{leg("0.92", false), leg("1.1", true)}, "50", 2, 2, model.RoundingModeHalfUp, "59.78"},
+		{"zero", Path{leg("0.92", false)}, "0", 2, 2, model.RoundingModeHalfUp, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := decimal.RequireFromString(tt.target)
+
+			got := tt.path.SourceAmount(target, tt.sourcePlaces, tt.targetPlaces, tt.mode)
+
+			if tt.path.Convert(got, tt.targetPlaces, tt.mode).LessThan(target) {
+				t.Fatalf("SourceAmount(%s) = %s doesn't convert to the target amount", tt.target, got)
+			}
+
+			below := got.Sub(decimal.New(1, -tt.sourcePlaces))
+
+			if got.IsPositive() && tt.path.Convert(below, tt.targetPlaces, tt.mode).GreaterThanOrEqual(target) {
+				t.Errorf("SourceAmount(%s) = %s isn't the smallest, %s converts to the target amount as well", tt.target, got, below)
+			}
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("SourceAmount(%s) = %s, want %s", tt.target, got, tt.want)
+			}
+		})
+	}
+}
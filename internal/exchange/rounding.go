@@ -0,0 +1,60 @@
+package exchange
+
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultRoundingMode is used when neither the request nor the target currency
+// specifies a rounding mode.
+const DefaultRoundingMode = model.RoundingModeHalfUp
+
+// Round rounds the value to the given number of decimal places. Half up rounds
+// ties away from zero.
+func Round(value decimal.Decimal, places int32, mode model.RoundingMode) decimal.Decimal {
+	return roundQuotient(value, decimal.NewFromInt(1), places, mode)
+}
+
+// roundQuotient rounds numerator / denominator using the given mode without
+// rounding the quotient twice.
+func roundQuotient(
+	numerator decimal.Decimal,
+	denominator decimal.Decimal,
+	places int32,
+	mode model.RoundingMode,
+) decimal.Decimal {
+	quotient, remainder := numerator.QuoRem(denominator, places)
+
+	if remainder.IsZero() {
+		return quotient
+	}
+
+	unit := decimal.New(1, -places)
+	positive := numerator.Sign()*denominator.Sign() > 0
+
+	// Compares the remainder with the half of the last digit
+	half := remainder.Abs().Mul(decimal.NewFromInt(2)).Cmp(denominator.Abs().Mul(unit))
+
+	var awayFromZero bool
+
+	switch mode {
+	case model.RoundingModeFloor:
+		awayFromZero = !positive
+	case model.RoundingModeCeiling:
+		awayFromZero = positive
+	case model.RoundingModeHalfEven:
+		odd := quotient.Shift(places).BigInt().Bit(0) == 1
+		awayFromZero = half > 0 || (half == 0 && odd)
+	default:
+		awayFromZero = half >= 0
+	}
+
+	if !awayFromZero {
+		return quotient
+	}
+
+	if positive {
+		return quotient.Add(unit)
+	}
+	return quotient.Sub(unit)
+}
@@ -0,0 +1,61 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+func TestRoundQuotient(t *testing.T) {
+	tests := []struct {
+		name        string
+		numerator   string
+		denominator string
+		places      int32
+		mode        model.RoundingMode
+		want        string
+	}{
+		{"exact", "10", "4", 2, model.RoundingModeHalfUp, "2.5"},
+		{"half up tie", "1", "8", 2, model.RoundingModeHalfUp, "0.13"},
+		{"half up below tie", "1", "3", 2, model.RoundingModeHalfUp, "0.33"},
+		{"half up negative tie", "-1", "8", 2, model.RoundingModeHalfUp, "-0.13"},
+		{"half even tie to even", "1", "8", 2, model.RoundingModeHalfEven, "0.12"},
+		{"half even tie to odd", "3", "8", 2, model.RoundingModeHalfEven, "0.38"},
+		{"half even above tie", "2", "3", 2, model.RoundingModeHalfEven, "0.67"},
+		{"floor", "2", "3", 2, model.RoundingModeFloor, "0.66"},
+		{"floor negative", "-2", "3", 2, model.RoundingModeFloor, "-0.67"},
+		{"ceiling", "1", "3", 2, model.RoundingModeCeiling, "0.34"},
+		{"ceiling negative", "-1", "3", 2, model.RoundingModeCeiling, "-0.33"},
+		{"no minor units", "5", "2", 0, model.RoundingModeHalfUp, "3"},
+		{"no minor units half even", "5", "2", 0, model.RoundingModeHalfEven, "2"},
+		{"three minor units", "10", "3", 3, model.RoundingModeHalfUp, "3.333"},
+		// Rounding the quotient to 3 places first would give 0.125 and then
+		// 0.13
+		{"no double rounding", "0.12499", "1", 2, model.RoundingModeHalfUp, "0.12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundQuotient(
+				decimal.RequireFromString(tt.numerator),
+				decimal.RequireFromString(tt.denominator),
+				tt.places,
+				tt.mode,
+			)
+
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("roundQuotient(%s, %s, %d, %s) = %s, want %s",
+					tt.numerator, tt.denominator, tt.places, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	got := Round(decimal.RequireFromString("2.345"), 2, model.RoundingModeHalfUp)
+
+	if !got.Equal(decimal.RequireFromString("2.35")) {
+		t.Errorf("Round(2.345, 2, halfUp) = %s, want 2.35", got)
+	}
+}
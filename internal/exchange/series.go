@@ -0,0 +1,121 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// MaxSeriesBuckets limits the size of a single series response
+const MaxSeriesBuckets = 10000
+
+type Bucket struct {
+	Start time.Time
+	End   time.Time
+	Open  decimal.Decimal
+	High  decimal.Decimal
+	Low   decimal.Decimal
+	Close decimal.Decimal
+	Count int
+}
+
+// ParseInterval parses a positive duration, additionally accepting days and
+// weeks, e.g. 1d or 2w.
+func ParseInterval(value string) (time.Duration, error) {
+	var interval time.Duration
+	var err error
+
+	switch {
+	case strings.HasSuffix(value, "d"):
+		interval, err = parseUnits(strings.TrimSuffix(value, "d"), 24*time.Hour)
+	case strings.HasSuffix(value, "w"):
+		interval, err = parseUnits(strings.TrimSuffix(value, "w"), 7*24*time.Hour)
+	default:
+		interval, err = time.ParseDuration(value)
+	}
+
+	if err != nil || interval <= 0 {
+		return 0, errors.New(fmt.Sprintf("Couldn't parse interval from '%s'", value))
+	}
+
+	return interval, nil
+}
+
+func parseUnits(value string, unit time.Duration) (time.Duration, error) {
+	count, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(count) * unit, nil
+}
+
+// Series aggregates rate changes into buckets of the given interval between
+// from and to. The rate in effect at from, if any, opens the first bucket and
+// isn't counted as a change; buckets without changes carry the previous close.
+// A removal isn't counted either, no close is carried after it until the rate
+// is created again.
+func Series(
+	initial *model.ExchangeRate,
+	history []model.ExchangeRateHistory,
+	from time.Time,
+	to time.Time,
+	interval time.Duration,
+) []Bucket {
+	var buckets []Bucket
+
+	var last *decimal.Decimal
+	if initial != nil {
+		last = &initial.Rate
+	}
+
+	next := 0
+
+	for start := from; start.Before(to); start = start.Add(interval) {
+		end := start.Add(interval)
+		if end.After(to) {
+			end = to
+		}
+
+		bucket := Bucket{Start: start, End: end}
+		filled := last != nil
+
+		if filled {
+			bucket.Open, bucket.High, bucket.Low, bucket.Close = *last, *last, *last, *last
+		}
+
+		for ; next < len(history) && history[next].EffectiveFrom.Before(end); next++ {
+			if history[next].Removed {
+				last = nil
+				continue
+			}
+
+			rate := history[next].Rate
+
+			if !filled {
+				bucket.Open, bucket.High, bucket.Low = rate, rate, rate
+				filled = true
+			}
+
+			bucket.High = decimal.Max(bucket.High, rate)
+			bucket.Low = decimal.Min(bucket.Low, rate)
+			bucket.Close = rate
+			bucket.Count++
+			last = &history[next].Rate
+		}
+
+		if !filled {
+			continue
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+var seriesFrom = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func historyEntry(hours int, rate string, removed bool) model.ExchangeRateHistory {
+	return model.ExchangeRateHistory{
+		Rate:          decimal.RequireFromString(rate),
+		EffectiveFrom: seriesFrom.Add(time.Duration(hours) * time.Hour),
+		Removed:       removed,
+	}
+}
+
+func TestSeries(t *testing.T) {
+	initial := &model.ExchangeRate{Rate: decimal.RequireFromString("1")}
+
+	tests := []struct {
+		name    string
+		initial *model.ExchangeRate
+		history []model.ExchangeRateHistory
+		// Open, high, low, close and count of each bucket
+		want [][5]string
+	}{
+		{
+			name:    "initial rate carried",
+			initial: initial,
+			want:    [][5]string{{"1", "1", "1", "1", "0"}, {"1", "1", "1", "1", "0"}, {"1", "1", "1", "1", "0"}},
+		},
+		{
+			name:    "changes",
+			initial: initial,
+			history: []model.ExchangeRateHistory{
+				historyEntry(1, "3", false),
+				historyEntry(2, "2", false),
+				historyEntry(25, "0.5", false),
+			},
+			want: [][5]string{{"1", "3", "1", "2", "2"}, {"2", "2", "0.5", "0.5", "1"}, {"0.5", "0.5", "0.5", "0.5", "0"}},
+		},
+		{
+			name: "no rate before the first change",
+			history: []model.ExchangeRateHistory{
+				historyEntry(25, "2", false),
+			},
+			want: [][5]string{{"2", "2", "2", "2", "1"}, {"2", "2", "2", "2", "0"}},
+		},
+		{
+			name:    "removal stops the carry",
+			initial: initial,
+			history: []model.ExchangeRateHistory{
+				historyEntry(1, "2", false),
+				historyEntry(2, "2", true),
+			},
+			want: [][5]string{{"1", "2", "1", "2", "1"}},
+		},
+		{
+			name:    "created again after a removal",
+			initial: initial,
+			history: []model.ExchangeRateHistory{
+				historyEntry(1, "1", true),
+				historyEntry(49, "4", false),
+			},
+			want: [][5]string{{"1", "1", "1", "1", "0"}, {"4", "4", "4", "4", "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := Series(tt.initial, tt.history, seriesFrom, seriesFrom.Add(72*time.Hour), 24*time.Hour)
+
+			if len(buckets) != len(tt.want) {
+				t.Fatalf("Series() returned %d buckets, want %d", len(buckets), len(tt.want))
+			}
+
+			for i, bucket := range buckets {
+				got := [5]string{
+					bucket.Open.String(), bucket.High.String(), bucket.Low.String(), bucket.Close.String(),
+					strconv.Itoa(bucket.Count),
+				}
+
+				if got != tt.want[i] {
+					t.Errorf("Bucket %d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
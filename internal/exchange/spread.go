@@ -0,0 +1,34 @@
+package exchange
+
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+var basisPoints = decimal.NewFromInt(10000)
+
+// Bid returns the rate at which the base currency of the exchange rate is
+// bought from the customer.
+func Bid(exchangeRate model.ExchangeRate) decimal.Decimal {
+	if exchangeRate.Bid.Valid {
+		return exchangeRate.Bid.Decimal
+	}
+	if exchangeRate.SpreadBps.Valid {
+		markup := exchangeRate.Rate.Mul(exchangeRate.SpreadBps.Decimal).Div(basisPoints)
+		return exchangeRate.Rate.Sub(markup)
+	}
+	return exchangeRate.Rate
+}
+
+// Ask returns the rate at which the base currency of the exchange rate is sold
+// to the customer.
+func Ask(exchangeRate model.ExchangeRate) decimal.Decimal {
+	if exchangeRate.Ask.Valid {
+		return exchangeRate.Ask.Decimal
+	}
+	if exchangeRate.SpreadBps.Valid {
+		markup := exchangeRate.Rate.Mul(exchangeRate.SpreadBps.Decimal).Div(basisPoints)
+		return exchangeRate.Rate.Add(markup)
+	}
+	return exchangeRate.Rate
+}
@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+)
+
+type StaleRatePolicy string
+
+const (
+	// Exchanges with stale rates are refused
+	StaleRatePolicyReject StaleRatePolicy = "reject"
+	// Exchanges with stale rates are made and flagged as stale
+	StaleRatePolicyFlag StaleRatePolicy = "flag"
+)
+
+var StaleRatePolicies = []StaleRatePolicy{StaleRatePolicyReject, StaleRatePolicyFlag}
+
+// Staleness decides when an exchange rate is too old to be used. A max age of
+// 0 means rates never become stale.
+type Staleness struct {
+	MaxAge time.Duration
+	// Max ages overriding MaxAge, keyed by currency code pairs, e.g. USDEUR
+	PairMaxAges map[string]time.Duration
+	Policy      StaleRatePolicy
+}
+
+// Age returns how long the exchange rate has been in effect at the given time
+func Age(exchangeRate model.ExchangeRate, at time.Time) time.Duration {
+	return at.Sub(exchangeRate.EffectiveFrom)
+}
+
+func (s Staleness) MaxAgeOf(codePair string) time.Duration {
+	if maxAge, ok := s.PairMaxAges[codePair]; ok {
+		return maxAge
+	}
+	return s.MaxAge
+}
+
+// IsStale tells whether the exchange rate of the currency code pair is older
+// than its max age at the given time
+func (s Staleness) IsStale(codePair string, exchangeRate model.ExchangeRate, at time.Time) bool {
+	maxAge := s.MaxAgeOf(codePair)
+	return maxAge > 0 && Age(exchangeRate, at) > maxAge
+}
@@ -0,0 +1,352 @@
+package fakeprovider
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Server is a stand-in rate provider serving rates in the formats the rate
+// ingestion understands, so it can run without network access
+//
+//	GET /rates                  {"base": "USD", "rates": {"EUR": "0.92"}}
+//	GET /eurofxref-daily.xml    ECB reference rates against EUR
+//	GET /faults                 faults injected into every response
+//	PUT /faults                 replaces the injected faults
+//
+// Faults can also be injected into a single response with the latency,
+// status and malformed query parameters, e.g. /rates?status=503.
+type Server struct {
+	mux *http.ServeMux
+
+	mu         sync.Mutex
+	base       string
+	rates      map[string]decimal.Decimal
+	mode       Mode
+	volatility float64
+	random     *rand.Rand
+	faults     Faults
+}
+
+type Mode string
+
+const (
+	// Rates never change
+	ModeFixed Mode = "fixed"
+	// Rates take a random step before every response
+	ModeRandomWalk Mode = "randomWalk"
+)
+
+var Modes = []Mode{ModeFixed, ModeRandomWalk}
+
+// Faults are injected into responses with the given probabilities
+type Faults struct {
+	Latency time.Duration
+	// Probability of responding with 500 Internal Server Error
+	ErrorRate float64
+	// Probability of responding with a truncated payload
+	MalformedRate float64
+}
+
+// faultsBody is Faults with the latency as a Go duration, e.g.
+//
+//	{"latency": "2s", "errorRate": 0.5, "malformedRate": 0}
+type faultsBody struct {
+	Latency       string  `json:"latency"`
+	ErrorRate     float64 `json:"errorRate"`
+	MalformedRate float64 `json:"malformedRate"`
+}
+
+type Options struct {
+	Base  string
+	Rates map[string]decimal.Decimal
+	Mode  Mode
+	// Standard deviation of a random walk step, in percent of the rate
+	Volatility float64
+	// Seed of the random walk and faults, the same seed gives the same rates
+	Seed   uint64
+	Faults Faults
+}
+
+// ratePrecision is the number of decimal places of the served rates
+const ratePrecision = 6
+
+func New(options Options) *Server {
+	s := &Server{
+		mux:        http.NewServeMux(),
+		base:       options.Base,
+		rates:      maps.Clone(options.Rates),
+		mode:       options.Mode,
+		volatility: options.Volatility,
+		random:     rand.New(rand.NewPCG(options.Seed, options.Seed)),
+		faults:     options.Faults,
+	}
+
+	s.mux.HandleFunc("GET /rates", s.getRates)
+	s.mux.HandleFunc("GET /eurofxref-daily.xml", s.getECBRates)
+	s.mux.HandleFunc("GET /faults", s.getFaults)
+	s.mux.HandleFunc("PUT /faults", s.putFaults)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetFaults replaces the faults injected into every response
+func (s *Server) SetFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = faults
+}
+
+// Rates returns the rates served last, after a step of the random walk
+func (s *Server) Rates() map[string]decimal.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return maps.Clone(s.rates)
+}
+
+type jsonRates struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+func (s *Server) getRates(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /rates was called")
+
+	rates, malformed, ok := s.respond(w, r)
+
+	if !ok {
+		return
+	}
+
+	body, _ := json.Marshal(jsonRates{Base: s.base, Rates: rates})
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(truncate(body, malformed))
+}
+
+type ecbEnvelope struct {
+	XMLName xml.Name `xml:"gesmes:Envelope"`
+	Gesmes  string   `xml:"xmlns:gesmes,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Subject string   `xml:"gesmes:subject"`
+	Day     struct {
+		Time  string    `xml:"time,attr"`
+		Rates []ecbRate `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+type ecbRate struct {
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"`
+}
+
+// getECBRates serves the rates as ECB reference rates, crossing them through
+// EUR when the base currency isn't EUR
+func (s *Server) getECBRates(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /eurofxref-daily.xml was called")
+
+	rates, malformed, ok := s.respond(w, r)
+
+	if !ok {
+		return
+	}
+
+	euroRate := decimal.NewFromInt(1)
+
+	if s.base != "EUR" {
+		rate, exists := rates["EUR"]
+
+		if !exists {
+			http.Error(w, "EUR rate is required for the ECB format", http.StatusInternalServerError)
+			return
+		}
+
+		euroRate = rate
+		rates[s.base] = decimal.NewFromInt(1)
+		delete(rates, "EUR")
+	}
+
+	envelope := ecbEnvelope{
+		Gesmes:  "http://www.gesmes.org/xml/2002-08-01",
+		Xmlns:   "http://www.ecb.int/vocabulary/2002-08-01/eurofxref",
+		Subject: "Reference rates",
+	}
+	envelope.Day.Time = time.Now().UTC().Format(time.DateOnly)
+
+	for _, code := range sortedCodes(rates) {
+		envelope.Day.Rates = append(envelope.Day.Rates, ecbRate{
+			Currency: code,
+			Rate:     rates[code].DivRound(euroRate, ratePrecision).String(),
+		})
+	}
+
+	body, _ := xml.MarshalIndent(envelope, "", "\t")
+
+	w.Header().Add("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(truncate(append([]byte(xml.Header), body...), malformed))
+}
+
+// respond takes a step of the random walk and injects the faults. It returns
+// the rates to serve and whether the payload has to be malformed, or false when
+// an error was already written.
+func (s *Server) respond(w http.ResponseWriter, r *http.Request) (map[string]decimal.Decimal, bool, bool) {
+	s.mu.Lock()
+
+	faults := s.faults
+	failed := s.random.Float64() < faults.ErrorRate
+	malformed := s.random.Float64() < faults.MalformedRate
+
+	if s.mode == ModeRandomWalk {
+		// Stepped in a fixed order for the same seed to give the same rates
+		for _, code := range sortedCodes(s.rates) {
+			step := decimal.NewFromFloat(s.random.NormFloat64() * s.volatility).Shift(-2)
+			rate := s.rates[code].Add(s.rates[code].Mul(step)).Round(ratePrecision)
+
+			if rate.IsPositive() {
+				s.rates[code] = rate
+			}
+		}
+	}
+
+	rates := maps.Clone(s.rates)
+
+	s.mu.Unlock()
+
+	query := r.URL.Query()
+	status := http.StatusInternalServerError
+
+	if latencyStr := query.Get("latency"); len(latencyStr) != 0 {
+		latency, err := time.ParseDuration(latencyStr)
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Couldn't parse latency from '%s'", latencyStr), http.StatusBadRequest)
+			return nil, false, false
+		}
+
+		faults.Latency = latency
+	}
+
+	if statusStr := query.Get("status"); len(statusStr) != 0 {
+		var err error
+		status, err = strconv.Atoi(statusStr)
+
+		if err != nil || status < 200 || status > 599 {
+			http.Error(w, fmt.Sprintf("Couldn't parse status from '%s'", statusStr), http.StatusBadRequest)
+			return nil, false, false
+		}
+
+		failed = status != http.StatusOK
+	}
+
+	if malformedStr := query.Get("malformed"); len(malformedStr) != 0 {
+		var err error
+		malformed, err = strconv.ParseBool(malformedStr)
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Couldn't parse malformed from '%s'", malformedStr), http.StatusBadRequest)
+			return nil, false, false
+		}
+	}
+
+	select {
+	case <-time.After(faults.Latency):
+	case <-r.Context().Done():
+		return nil, false, false
+	}
+
+	if failed {
+		http.Error(w, http.StatusText(status), status)
+		return nil, false, false
+	}
+
+	return rates, malformed, true
+}
+
+func sortedCodes(rates map[string]decimal.Decimal) []string {
+	codes := make([]string, 0, len(rates))
+
+	for code := range rates {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+
+	return codes
+}
+
+// truncate cuts the payload in half when it has to be malformed
+func truncate(body []byte, malformed bool) []byte {
+	if malformed {
+		return body[:len(body)/2]
+	}
+	return body
+}
+
+func (s *Server) getFaults(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /faults was called")
+
+	s.mu.Lock()
+	faults := s.faults
+	s.mu.Unlock()
+
+	writeFaults(w, faults)
+}
+
+func (s *Server) putFaults(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("PUT /faults was called")
+
+	var body faultsBody
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("Couldn't decode faults: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	faults := Faults{ErrorRate: body.ErrorRate, MalformedRate: body.MalformedRate}
+
+	if len(body.Latency) != 0 {
+		var err error
+		faults.Latency, err = time.ParseDuration(body.Latency)
+
+		if err != nil || faults.Latency < 0 {
+			http.Error(w, fmt.Sprintf("Couldn't parse latency from '%s'", body.Latency), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if faults.ErrorRate < 0 || faults.ErrorRate > 1 || faults.MalformedRate < 0 || faults.MalformedRate > 1 {
+		http.Error(w, "Error and malformed rates must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
+
+	s.SetFaults(faults)
+
+	writeFaults(w, faults)
+}
+
+func writeFaults(w http.ResponseWriter, faults Faults) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(faultsBody{
+		Latency:       faults.Latency.String(),
+		ErrorRate:     faults.ErrorRate,
+		MalformedRate: faults.MalformedRate,
+	})
+}
@@ -0,0 +1,308 @@
+package fakeprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/shopspring/decimal"
+)
+
+func testOptions() Options {
+	return Options{
+		Base: "USD",
+		Rates: map[string]decimal.Decimal{
+			"EUR": decimal.RequireFromString("0.92"),
+			"GBP": decimal.RequireFromString("0.79"),
+		},
+		Mode: ModeFixed,
+		Seed: 1,
+	}
+}
+
+// newTestServer serves the fake provider and counts the requests it gets
+func newTestServer(t *testing.T, fake *Server) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func quoteRates(quotes []provider.Quote) map[string]string {
+	rates := make(map[string]string)
+
+	for _, quote := range quotes {
+		rates[quote.BaseCurrencyCode+quote.TargetCurrencyCode] = quote.Rate.String()
+	}
+
+	return rates
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider func(url string, client *http.Client) provider.RateProvider
+		path     string
+		want     map[string]string
+	}{
+		{
+			name: "json",
+			provider: func(url string, client *http.Client) provider.RateProvider {
+				return provider.NewJSONProvider("fake", url, client)
+			},
+			path: "/rates",
+			want: map[string]string{"USDEUR": "0.92", "USDGBP": "0.79"},
+		},
+		{
+			name: "ecb",
+			provider: func(url string, client *http.Client) provider.RateProvider {
+				return provider.NewECBProvider("fake", url, client)
+			},
+			path: "/eurofxref-daily.xml",
+			want: map[string]string{"EURUSD": "1.086957", "EURGBP": "0.858696"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newTestServer(t, New(testOptions()))
+
+			quotes, err := tt.provider(server.URL+tt.path, server.Client()).FetchRates(context.Background())
+
+			if err != nil {
+				t.Fatalf("FetchRates() failed: %s", err)
+			}
+
+			rates := quoteRates(quotes)
+
+			if len(rates) != len(tt.want) {
+				t.Fatalf("FetchRates() = %v, want %v", rates, tt.want)
+			}
+			for pair, rate := range tt.want {
+				if rates[pair] != rate {
+					t.Errorf("Rate of %s = %s, want %s", pair, rates[pair], rate)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		timeout time.Duration
+		wantErr string
+	}{
+		{"status", "?status=503", time.Second, "Unexpected response status 503"},
+		{"malformed", "?malformed=true", time.Second, "Couldn't decode rates"},
+		{"latency", "?latency=500ms", 50 * time.Millisecond, "Client.Timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newTestServer(t, New(testOptions()))
+
+			client := server.Client()
+			client.Timeout = tt.timeout
+
+			_, err := provider.NewJSONProvider("fake", server.URL+"/rates"+tt.query, client).FetchRates(context.Background())
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FetchRates() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPutFaults(t *testing.T) {
+	fake := New(testOptions())
+	server, _ := newTestServer(t, fake)
+
+	request, _ := http.NewRequest(http.MethodPut, server.URL+"/faults", strings.NewReader(`{"errorRate": 1}`))
+	resp, err := server.Client().Do(request)
+
+	if err != nil {
+		t.Fatalf("PUT /faults failed: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT /faults responded with %s", resp.Status)
+	}
+
+	_, err = provider.NewJSONProvider("fake", server.URL+"/rates", server.Client()).FetchRates(context.Background())
+
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("FetchRates() error = %v, want 500 with every response failing", err)
+	}
+}
+
+func TestRandomWalkIsSeeded(t *testing.T) {
+	options := testOptions()
+	options.Mode = ModeRandomWalk
+	options.Volatility = 1
+
+	first, second := New(options), New(options)
+	firstServer, _ := newTestServer(t, first)
+	secondServer, _ := newTestServer(t, second)
+
+	for _, server := range []*httptest.Server{firstServer, secondServer} {
+		resp, err := server.Client().Get(server.URL + "/rates")
+
+		if err != nil {
+			t.Fatalf("GET /rates failed: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	firstRates, secondRates := first.Rates(), second.Rates()
+
+	for code, rate := range firstRates {
+		if !rate.Equal(secondRates[code]) {
+			t.Errorf("Rate of %s differs between servers with the same seed: %s and %s", code, rate, secondRates[code])
+		}
+		if rate.Equal(options.Rates[code]) {
+			t.Errorf("Rate of %s didn't take a random step", code)
+		}
+	}
+}
+
+func testResilience() provider.Resilience {
+	return provider.Resilience{
+		MaxAttempts:      3,
+		Backoff:          time.Millisecond,
+		MaxBackoff:       5 * time.Millisecond,
+		FailureThreshold: 2,
+		Cooldown:         50 * time.Millisecond,
+	}
+}
+
+func TestResilientProviderRetries(t *testing.T) {
+	fake := New(testOptions())
+	fake.SetFaults(Faults{ErrorRate: 1})
+
+	var requests atomic.Int32
+
+	// Recovers after the first failed response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.ServeHTTP(w, r)
+
+		if requests.Add(1) == 1 {
+			fake.SetFaults(Faults{})
+		}
+	}))
+	defer server.Close()
+
+	resilientProvider := provider.NewResilientProvider(
+		provider.NewJSONProvider("fake", server.URL+"/rates", server.Client()),
+		testResilience(),
+	)
+
+	quotes, err := resilientProvider.FetchRates(context.Background())
+
+	if err != nil {
+		t.Fatalf("FetchRates() failed: %s", err)
+	}
+	if len(quotes) != 2 {
+		t.Errorf("FetchRates() returned %d quotes, want 2", len(quotes))
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Provider got %d requests, want 2", got)
+	}
+
+	status := resilientProvider.Status()
+
+	if status.State != provider.CircuitClosed || status.ConsecutiveFailures != 0 || status.LastSuccess == nil {
+		t.Errorf("Status = %+v, want a closed circuit after a success", status)
+	}
+}
+
+func TestResilientProviderOpensCircuit(t *testing.T) {
+	fake := New(testOptions())
+	server, requests := newTestServer(t, fake)
+
+	resilience := testResilience()
+	resilientProvider := provider.NewResilientProvider(
+		provider.NewJSONProvider("fake", server.URL+"/rates?status=503", server.Client()),
+		resilience,
+	)
+
+	for fetch := 1; fetch <= resilience.FailureThreshold; fetch++ {
+		if _, err := resilientProvider.FetchRates(context.Background()); err == nil {
+			t.Fatalf("Fetch %d succeeded, want it to fail", fetch)
+		}
+	}
+
+	if got, want := requests.Load(), int32(resilience.FailureThreshold*resilience.MaxAttempts); got != want {
+		t.Errorf("Provider got %d requests, want %d", got, want)
+	}
+	if state := resilientProvider.Status().State; state != provider.CircuitOpen {
+		t.Fatalf("State = %s, want open after %d failed fetches", state, resilience.FailureThreshold)
+	}
+
+	_, err := resilientProvider.FetchRates(context.Background())
+
+	if !errors.Is(err, provider.CircuitOpenError) {
+		t.Errorf("FetchRates() error = %v, want the circuit open", err)
+	}
+	if got, want := requests.Load(), int32(resilience.FailureThreshold*resilience.MaxAttempts); got != want {
+		t.Errorf("Provider got %d requests, want none while the circuit is open", got-want)
+	}
+
+	// A single failed probe after the cooldown opens the circuit again
+	time.Sleep(resilience.Cooldown)
+
+	if _, err := resilientProvider.FetchRates(context.Background()); err == nil || errors.Is(err, provider.CircuitOpenError) {
+		t.Fatalf("FetchRates() error = %v, want the probe to fail", err)
+	}
+	if got, want := requests.Load(), int32(resilience.FailureThreshold*resilience.MaxAttempts+1); got != want {
+		t.Errorf("Provider got %d requests, want %d with a single probe", got, want)
+	}
+	if state := resilientProvider.Status().State; state != provider.CircuitOpen {
+		t.Errorf("State = %s, want open after a failed probe", state)
+	}
+}
+
+func TestResilientProviderClosesCircuit(t *testing.T) {
+	fake := New(testOptions())
+	fake.SetFaults(Faults{ErrorRate: 1})
+	server, _ := newTestServer(t, fake)
+
+	resilience := testResilience()
+	resilientProvider := provider.NewResilientProvider(
+		provider.NewJSONProvider("fake", server.URL+"/rates", server.Client()),
+		resilience,
+	)
+
+	for fetch := 1; fetch <= resilience.FailureThreshold; fetch++ {
+		resilientProvider.FetchRates(context.Background())
+	}
+
+	if state := resilientProvider.Status().State; state != provider.CircuitOpen {
+		t.Fatalf("State = %s, want open", state)
+	}
+
+	fake.SetFaults(Faults{})
+	time.Sleep(resilience.Cooldown)
+
+	if _, err := resilientProvider.FetchRates(context.Background()); err != nil {
+		t.Fatalf("FetchRates() after the cooldown failed: %s", err)
+	}
+	if state := resilientProvider.Status().State; state != provider.CircuitClosed {
+		t.Errorf("State = %s, want closed after a successful probe", state)
+	}
+}
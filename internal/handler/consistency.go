@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/response"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+type ConsistencyHandler struct {
+	exchangeRateStore *store.ExchangeRateStore
+	currencyStore     *store.CurrencyStore
+	threshold         decimal.Decimal
+}
+
+func NewConsistencyHandler(
+	exchangeRateStore *store.ExchangeRateStore,
+	currencyStore *store.CurrencyStore,
+	threshold decimal.Decimal,
+) *ConsistencyHandler {
+	return &ConsistencyHandler{
+		exchangeRateStore: exchangeRateStore,
+		currencyStore:     currencyStore,
+		threshold:         threshold,
+	}
+}
+
+func (c *ConsistencyHandler) GetConsistencyReport(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /exchangeRates/consistency was called")
+
+	threshold := c.threshold
+	thresholdStr := r.URL.Query().Get("threshold")
+
+	if len(thresholdStr) != 0 {
+		var err error
+		threshold, err = decimal.NewFromString(thresholdStr)
+
+		if err != nil || threshold.IsNegative() {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{
+				Message: fmt.Sprintf("Threshold must be a non-negative number, got: %s", thresholdStr),
+			})
+			return
+		}
+	}
+
+	exchangeRates, err := c.exchangeRateStore.FindAll()
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	inconsistencies := exchange.FindInconsistencies(exchange.NewGraph(exchangeRates), threshold)
+
+	report := response.ConsistencyReport{
+		Threshold:       threshold,
+		Inconsistencies: []response.Inconsistency{},
+	}
+
+	for _, inconsistency := range inconsistencies {
+		inconsistencyResponse, err := c.toResponse(inconsistency)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+
+		report.Inconsistencies = append(report.Inconsistencies, *inconsistencyResponse)
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+func (c *ConsistencyHandler) toResponse(inconsistency exchange.Inconsistency) (*response.Inconsistency, error) {
+	codes, err := inconsistency.Cycle.Codes(c.currencyStore.FindById)
+
+	if err != nil {
+		return nil, err
+	}
+
+	inconsistencyResponse := response.Inconsistency{
+		Path:      codes,
+		Product:   inconsistency.Product,
+		Deviation: inconsistency.Deviation,
+	}
+
+	for _, leg := range inconsistency.Cycle {
+		baseCurrency, err := c.currencyStore.FindById(leg.ExchangeRate.BaseCurrencyId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		targetCurrency, err := c.currencyStore.FindById(leg.ExchangeRate.TargetCurrencyId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		inconsistencyResponse.ExchangeRates = append(inconsistencyResponse.ExchangeRates, response.ExchangeRate{
+			Id:             leg.ExchangeRate.Id,
+			BaseCurrency:   *baseCurrency,
+			TargetCurrency: *targetCurrency,
+			Rate:           leg.ExchangeRate.Rate,
+			Bid:            exchange.Bid(leg.ExchangeRate),
+			Ask:            exchange.Ask(leg.ExchangeRate),
+			EffectiveFrom:  leg.ExchangeRate.EffectiveFrom,
+		})
+	}
+
+	return &inconsistencyResponse, nil
+}
@@ -3,14 +3,20 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/krios2146/currency-exchange-api-go/internal/iso4217"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
 	"github.com/krios2146/currency-exchange-api-go/internal/response"
 	"github.com/krios2146/currency-exchange-api-go/internal/store"
 	"github.com/krios2146/currency-exchange-api-go/internal/validator"
 )
 
+const defaultMinorUnits = 2
+
 type CurrencyHandler struct {
 	store *store.CurrencyStore
 }
@@ -84,6 +90,8 @@ func (c *CurrencyHandler) AddCurrency(w http.ResponseWriter, r *http.Request) {
 	name := r.Form.Get("name")
 	code := r.Form.Get("code")
 	sign := r.Form.Get("sign")
+	minorUnitsStr := r.Form.Get("minorUnits")
+	roundingMode := r.Form.Get("roundingMode")
 
 	if len(name) == 0 {
 		w.Header().Add("Content-Type", "application/json")
@@ -92,7 +100,7 @@ func (c *CurrencyHandler) AddCurrency(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := validator.ValidateCurrencyCode(code); err != nil {
+	if err := validator.ValidateNewCurrencyCode(code); err != nil {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
@@ -105,7 +113,39 @@ func (c *CurrencyHandler) AddCurrency(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currency, err := c.store.Save(name, code, sign)
+	minorUnits := defaultMinorUnits
+
+	if len(minorUnitsStr) != 0 {
+		var err error
+		minorUnits, err = strconv.Atoi(minorUnitsStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{
+				Message: fmt.Sprintf("Couldn't parse minor units from '%s'", minorUnitsStr),
+			})
+			return
+		}
+	}
+
+	if err := validator.ValidateMinorUnits(minorUnits); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if len(roundingMode) != 0 {
+		if err := validator.ValidateRoundingMode(roundingMode); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	currency, err := c.store.Save(name, code, sign, int32(minorUnits), model.RoundingMode(roundingMode))
 
 	if errors.Is(err, store.CurrencyAlreadyExistsError) {
 		w.Header().Add("Content-Type", "application/json")
@@ -125,3 +165,202 @@ func (c *CurrencyHandler) AddCurrency(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(currency)
 }
+
+// GetCatalog lists the currencies of the ISO 4217 catalog, whether or not they
+// were created
+func (c *CurrencyHandler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /currencies/catalog was called")
+
+	catalogResponse := []response.CatalogCurrency{}
+
+	for _, entry := range iso4217.All() {
+		catalogResponse = append(catalogResponse, response.CatalogCurrency{
+			Code:       entry.Code,
+			Numeric:    entry.Numeric,
+			Name:       entry.Name,
+			Sign:       entry.Sign,
+			MinorUnits: entry.MinorUnits,
+			Countries:  entry.Countries,
+		})
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(catalogResponse)
+}
+
+// ImportCurrency creates a currency with the name, sign and minor units of the
+// ISO 4217 catalog
+func (c *CurrencyHandler) ImportCurrency(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	slog.Debug("POST /currencies/{code}/import was called with", "code", code)
+
+	if err := validator.ValidateCurrencyCode(code); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	entry, exists := iso4217.Lookup(code)
+
+	if !exists {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Currency %s is not in the ISO 4217 catalog", code),
+		})
+		return
+	}
+
+	currency, err := c.store.Save(entry.Name, entry.Code, entry.Sign, entry.MinorUnits, "")
+
+	if errors.Is(err, store.CurrencyAlreadyExistsError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(currency)
+}
+
+func (c *CurrencyHandler) UpdateCurrency(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	slog.Debug("PATCH /currency/{code} was called with", "code", code)
+
+	if err := validator.ValidateCurrencyCode(code); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	name := r.Form.Get("name")
+	sign := r.Form.Get("sign")
+
+	if len(name) == 0 && len(sign) == 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Either currency name or sign must be present in the request"})
+		return
+	}
+
+	currency, err := c.store.FindByCode(code)
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if len(name) != 0 {
+		currency.FullName = name
+	}
+	if len(sign) != 0 {
+		currency.Sign = sign
+	}
+
+	updatedCurrency, err := c.store.Update(*currency)
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedCurrency)
+}
+
+func (c *CurrencyHandler) DeleteCurrency(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	cascadeStr := r.URL.Query().Get("cascade")
+
+	slog.Debug("DELETE /currency/{code} was called with", "code", code)
+
+	if err := validator.ValidateCurrencyCode(code); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	cascade := false
+
+	if len(cascadeStr) != 0 {
+		var err error
+		cascade, err = strconv.ParseBool(cascadeStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse cascade from '%s'", cascadeStr)})
+			return
+		}
+	}
+
+	currency, err := c.store.FindByCode(code)
+
+	if err == nil {
+		err = c.store.Delete(currency.Id, cascade)
+	}
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if errors.Is(err, store.CurrencyInUseError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
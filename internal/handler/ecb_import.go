@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/importer"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/response"
+)
+
+// The full ECB history is about 7 MB
+const maxECBImportSize = 64 << 20
+
+// ECBImportHandler imports uploaded files only. The server doesn't download
+// them itself, so it can't be made to request arbitrary URLs. Imported rates
+// are written right away, so imports are refused while rate changes require
+// approval.
+type ECBImportHandler struct {
+	importer         *importer.ECBImporter
+	approvalRequired bool
+}
+
+func NewECBImportHandler(importer *importer.ECBImporter, approvalRequired bool) *ECBImportHandler {
+	return &ECBImportHandler{
+		importer:         importer,
+		approvalRequired: approvalRequired,
+	}
+}
+
+func (h *ECBImportHandler) ImportECB(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /exchangeRates/import/ecb was called")
+
+	if h.approvalRequired {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: "ECB imports are disabled while rate changes require approval",
+		})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxECBImportSize)
+
+	file, _, err := r.FormFile("file")
+
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "ECB rates file is not present in the request"})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	days, err := provider.ParseECB(file)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	result, err := h.importer.Import(days)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toECBImportResponse(result))
+}
+
+func toECBImportResponse(result *importer.ECBImport) *response.ECBImport {
+	createdCurrencies := result.CreatedCurrencies
+
+	if createdCurrencies == nil {
+		createdCurrencies = []model.Currency{}
+	}
+
+	return &response.ECBImport{
+		From:              result.From.Format(time.DateOnly),
+		To:                result.To.Format(time.DateOnly),
+		Days:              result.Days,
+		Imported:          result.Imported,
+		Skipped:           result.Skipped,
+		CreatedCurrencies: createdCurrencies,
+	}
+}
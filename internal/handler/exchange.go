@@ -5,24 +5,53 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
 	"github.com/krios2146/currency-exchange-api-go/internal/response"
 	"github.com/krios2146/currency-exchange-api-go/internal/store"
 	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	shortestPathStrategy = "shortest"
+	bestRatePathStrategy = "best"
+
+	maxReverseAdjustments = 100
+
+	maxBatchSize = 1000
+
+	directRate    = "direct"
+	inverseRate   = "inverse"
+	syntheticRate = "synthetic"
 )
 
 type ExchangeHandler struct {
 	exchangeRateStore *store.ExchangeRateStore
 	currencyStore     *store.CurrencyStore
+	feeRuleStore      *store.FeeRuleStore
+	pivotCurrencies   []string
+	staleness         exchange.Staleness
 }
 
-func NewExchangeHandler(exchangeRateStore *store.ExchangeRateStore, currencyStore *store.CurrencyStore) *ExchangeHandler {
+func NewExchangeHandler(
+	exchangeRateStore *store.ExchangeRateStore,
+	currencyStore *store.CurrencyStore,
+	feeRuleStore *store.FeeRuleStore,
+	pivotCurrencies []string,
+	staleness exchange.Staleness,
+) *ExchangeHandler {
 	return &ExchangeHandler{
 		exchangeRateStore: exchangeRateStore,
 		currencyStore:     currencyStore,
+		feeRuleStore:      feeRuleStore,
+		pivotCurrencies:   pivotCurrencies,
+		staleness:         staleness,
 	}
 }
 
@@ -34,8 +63,41 @@ func (c *ExchangeHandler) Exchange(w http.ResponseWriter, r *http.Request) {
 	baseCurrencyCode := query.Get("from")
 	targetCurrencyCode := query.Get("to")
 	amountStr := query.Get("amount")
-	amount, err := strconv.ParseFloat(amountStr, 64)
+	targetAmountStr := query.Get("toAmount")
+	strategy := query.Get("path")
+	pivotCurrencyCode := query.Get("via")
+	roundingMode := query.Get("rounding")
+	atStr := query.Get("at")
+	side := query.Get("side")
+	explainStr := query.Get("explain")
+
+	// Reverse exchange solves for the amount needed to receive toAmount
+	reverse := len(targetAmountStr) != 0
+
+	if reverse && len(amountStr) != 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Either amount or toAmount must be present, not both"})
+		return
+	}
+
+	var amount decimal.Decimal
+	var err error
+
+	if reverse {
+		amount, err = decimal.NewFromString(targetAmountStr)
+	} else {
+		amount, err = decimal.NewFromString(amountStr)
+	}
 
+	if err != nil && reverse {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Couldn't parse toAmount from '%s'", targetAmountStr),
+		})
+		return
+	}
 	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -57,7 +119,83 @@ func (c *ExchangeHandler) Exchange(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if amount < 0 {
+	if strategy == "" {
+		strategy = shortestPathStrategy
+	}
+	if strategy != shortestPathStrategy && strategy != bestRatePathStrategy {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Path must be either '%s' or '%s', got: %s", shortestPathStrategy, bestRatePathStrategy, strategy),
+		})
+		return
+	}
+
+	if len(pivotCurrencyCode) != 0 {
+		if err := validator.ValidateCurrencyCode(pivotCurrencyCode); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+		if pivotCurrencyCode == baseCurrencyCode || pivotCurrencyCode == targetCurrencyCode {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{
+				Message: "Pivot currency must differ from the base and target currencies",
+			})
+			return
+		}
+	}
+
+	if len(roundingMode) != 0 {
+		if err := validator.ValidateRoundingMode(roundingMode); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	if len(side) != 0 {
+		if err := validator.ValidateSide(side); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	explain := false
+
+	if len(explainStr) != 0 {
+		var err error
+		explain, err = strconv.ParseBool(explainStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse explain from '%s'", explainStr)})
+			return
+		}
+	}
+
+	var at *time.Time
+
+	if len(atStr) != 0 {
+		parsed, err := time.Parse(time.RFC3339, atStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse time from '%s'", atStr)})
+			return
+		}
+
+		at = &parsed
+	}
+
+	if amount.IsNegative() {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Amount cannot be negative"})
@@ -92,80 +230,553 @@ func (c *ExchangeHandler) Exchange(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Direct exchange
-	exchangeRate, err := c.exchangeRateStore.FindByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+	var path exchange.Path
 
-	if exchangeRate != nil {
-		convertedAmount := amount * exchangeRate.Rate
-
-		exchangeResponse := response.Exchange{
-			BaseCurrency:    *baseCurrency,
-			TargetCurrency:  *targetCurrency,
-			Amount:          amount,
-			Rate:            exchangeRate.Rate,
-			ConvertedAmount: round(convertedAmount, 2),
-		}
+	switch {
+	case len(pivotCurrencyCode) != 0:
+		path, err = c.findCrossPath(baseCurrencyCode, targetCurrencyCode, pivotCurrencyCode, at)
+	case strategy == bestRatePathStrategy:
+		path, err = c.findPath(baseCurrency.Id, targetCurrency.Id, strategy, at)
+	default:
+		path, err = c.findShortestPath(baseCurrencyCode, targetCurrencyCode, baseCurrency.Id, targetCurrency.Id, at)
+	}
 
+	if errors.Is(err, store.ExchangeRateNotFoundError) {
 		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(exchangeResponse)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	// Indirect exchange
-	exchangeRate, err = c.exchangeRateStore.FindByCurrencyCodes(targetCurrencyCode, baseCurrencyCode)
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
 
-	if exchangeRate != nil {
-		convertedAmount := amount * (1 / exchangeRate.Rate)
-
-		exchangeResponse := response.Exchange{
-			BaseCurrency:    *baseCurrency,
-			TargetCurrency:  *targetCurrency,
-			Amount:          amount,
-			Rate:            (1 / exchangeRate.Rate),
-			ConvertedAmount: round(convertedAmount, 2),
-		}
+	pathCodes, err := path.Codes(c.currencyStore.FindById)
 
+	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(exchangeResponse)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	// Cross exchange
-	usdToBaseExchangeRate, err := c.exchangeRateStore.FindByCurrencyCodes("USD", baseCurrencyCode)
-	usdToTargetExchangeRate, err := c.exchangeRateStore.FindByCurrencyCodes("USD", targetCurrencyCode)
-
-	if usdToBaseExchangeRate != nil && usdToTargetExchangeRate != nil {
-		convertedAmount := amount * (usdToTargetExchangeRate.Rate / usdToBaseExchangeRate.Rate)
-
-		exchangeResponse := response.Exchange{
-			BaseCurrency:    *baseCurrency,
-			TargetCurrency:  *targetCurrency,
-			Amount:          amount,
-			Rate:            (usdToTargetExchangeRate.Rate / usdToBaseExchangeRate.Rate),
-			ConvertedAmount: round(convertedAmount, 2),
-		}
+	feeRule, err := c.findFeeRule(baseCurrency.Id, targetCurrency.Id)
 
+	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(exchangeResponse)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	if errors.Is(err, store.ExchangeRateNotFoundError) {
+	pair := &exchangePair{
+		baseCurrency:   baseCurrency,
+		targetCurrency: targetCurrency,
+		path:           path,
+		pathCodes:      pathCodes,
+		feeRule:        feeRule,
+	}
+
+	staleAt := time.Now()
+	if at != nil {
+		staleAt = *at
+	}
+
+	stale, err := pair.checkStaleness(c.staleness, staleAt)
+
+	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(http.StatusUnprocessableEntity)
 		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
 		return
 	}
 
+	roundingMode = string(pair.roundingMode(model.RoundingMode(roundingMode)))
+
+	if reverse {
+		amount, err = pair.sourceAmount(amount, model.Side(side), model.RoundingMode(roundingMode))
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	exchangeResponse := pair.convert(amount, model.Side(side), model.RoundingMode(roundingMode))
+	exchangeResponse.Stale = stale
+
+	if explain {
+		exchangeResponse.Explanation = pair.explain(amount, model.Side(side), model.RoundingMode(roundingMode))
+	}
+
 	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(http.StatusInternalServerError)
-	json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
-	return
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exchangeResponse)
+}
+
+type batchExchangeItem struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Amount json.RawMessage `json:"amount"`
 }
 
-func round(value float64, precision int) float64 {
-	return math.Round(value*math.Pow10(precision)) / math.Pow10(precision)
+func (c *ExchangeHandler) ExchangeBatch(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /exchange/batch was called")
+
+	var items []batchExchangeItem
+
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Request body must be a JSON array of exchanges"})
+		return
+	}
+
+	if len(items) > maxBatchSize {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Batch cannot contain more than %d exchanges", maxBatchSize),
+		})
+		return
+	}
+
+	resolver := newPairResolver(c)
+	results := make([]response.BatchExchangeItem, len(items))
+
+	for i, item := range items {
+		results[i] = c.exchangeBatchItem(resolver, item)
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
+
+func (c *ExchangeHandler) exchangeBatchItem(resolver *pairResolver, item batchExchangeItem) response.BatchExchangeItem {
+	if err := validator.ValidateCurrencyCode(item.From); err != nil {
+		return batchError(http.StatusBadRequest, err)
+	}
+	if err := validator.ValidateCurrencyCode(item.To); err != nil {
+		return batchError(http.StatusBadRequest, err)
+	}
+
+	var amount decimal.Decimal
+
+	if len(item.Amount) == 0 || string(item.Amount) == "null" {
+		return batchError(http.StatusBadRequest, errors.New("Amount is missing"))
+	}
+	if err := amount.UnmarshalJSON(item.Amount); err != nil {
+		return batchError(http.StatusBadRequest, fmt.Errorf("Couldn't parse amount from '%s'", strings.Trim(string(item.Amount), `"`)))
+	}
+	if amount.IsNegative() {
+		return batchError(http.StatusBadRequest, errors.New("Amount cannot be negative"))
+	}
+
+	pair, err := resolver.resolve(item.From, item.To)
+
+	if errors.Is(err, store.CurrencyNotFoundError) || errors.Is(err, store.ExchangeRateNotFoundError) {
+		return batchError(http.StatusNotFound, err)
+	}
+	if err != nil {
+		return batchError(http.StatusInternalServerError, err)
+	}
+
+	stale, err := pair.checkStaleness(c.staleness, time.Now())
+
+	if err != nil {
+		return batchError(http.StatusUnprocessableEntity, err)
+	}
+
+	result := pair.convert(amount, "", pair.roundingMode(""))
+	result.Stale = stale
+
+	return response.BatchExchangeItem{Status: http.StatusOK, Result: &result}
+}
+
+func batchError(status int, err error) response.BatchExchangeItem {
+	return response.BatchExchangeItem{Status: status, Error: &response.ErrorResponse{Message: err.Error()}}
+}
+
+type resolvedPair struct {
+	pair *exchangePair
+	err  error
+}
+
+// pairResolver looks up every currency and currency pair at most once, so
+// repeated pairs in a batch don't hit the stores again
+type pairResolver struct {
+	handler    *ExchangeHandler
+	currencies map[string]*model.Currency
+	pairs      map[string]resolvedPair
+}
+
+func newPairResolver(handler *ExchangeHandler) *pairResolver {
+	return &pairResolver{
+		handler:    handler,
+		currencies: make(map[string]*model.Currency),
+		pairs:      make(map[string]resolvedPair),
+	}
+}
+
+func (r *pairResolver) resolve(baseCurrencyCode string, targetCurrencyCode string) (*exchangePair, error) {
+	key := baseCurrencyCode + targetCurrencyCode
+
+	if resolved, ok := r.pairs[key]; ok {
+		return resolved.pair, resolved.err
+	}
+
+	pair, err := r.resolvePair(baseCurrencyCode, targetCurrencyCode)
+	r.pairs[key] = resolvedPair{pair: pair, err: err}
+
+	return pair, err
+}
+
+func (r *pairResolver) resolvePair(baseCurrencyCode string, targetCurrencyCode string) (*exchangePair, error) {
+	baseCurrency, err := r.findCurrency(baseCurrencyCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	targetCurrency, err := r.findCurrency(targetCurrencyCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := r.handler.findShortestPath(baseCurrencyCode, targetCurrencyCode, baseCurrency.Id, targetCurrency.Id, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pathCodes, err := path.Codes(r.handler.currencyStore.FindById)
+
+	if err != nil {
+		return nil, err
+	}
+
+	feeRule, err := r.handler.findFeeRule(baseCurrency.Id, targetCurrency.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchangePair{
+		baseCurrency:   baseCurrency,
+		targetCurrency: targetCurrency,
+		path:           path,
+		pathCodes:      pathCodes,
+		feeRule:        feeRule,
+	}, nil
+}
+
+func (r *pairResolver) findCurrency(code string) (*model.Currency, error) {
+	if currency, ok := r.currencies[code]; ok {
+		return currency, nil
+	}
+
+	currency, err := r.handler.currencyStore.FindByCode(code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.currencies[code] = currency
+
+	return currency, nil
+}
+
+func (c *ExchangeHandler) findShortestPath(
+	baseCurrencyCode string,
+	targetCurrencyCode string,
+	baseCurrencyId int64,
+	targetCurrencyId int64,
+	at *time.Time,
+) (exchange.Path, error) {
+	// Direct and indirect exchange
+	leg, err := c.findLeg(baseCurrencyCode, targetCurrencyCode, at)
+
+	if leg != nil {
+		return exchange.Path{*leg}, nil
+	}
+	if !errors.Is(err, store.ExchangeRateNotFoundError) {
+		return nil, err
+	}
+
+	// Cross exchange
+	for _, pivotCurrencyCode := range c.pivotCurrencies {
+		if pivotCurrencyCode == baseCurrencyCode || pivotCurrencyCode == targetCurrencyCode {
+			continue
+		}
+
+		path, err := c.findCrossPath(baseCurrencyCode, targetCurrencyCode, pivotCurrencyCode, at)
+
+		if path != nil {
+			return path, nil
+		}
+		if !errors.Is(err, store.ExchangeRateNotFoundError) {
+			return nil, err
+		}
+	}
+
+	// Multi-hop exchange
+	return c.findPath(baseCurrencyId, targetCurrencyId, shortestPathStrategy, at)
+}
+
+func (c *ExchangeHandler) findCrossPath(
+	baseCurrencyCode string,
+	targetCurrencyCode string,
+	pivotCurrencyCode string,
+	at *time.Time,
+) (exchange.Path, error) {
+	baseToPivotLeg, err := c.findLeg(baseCurrencyCode, pivotCurrencyCode, at)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pivotToTargetLeg, err := c.findLeg(pivotCurrencyCode, targetCurrencyCode, at)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return exchange.Path{*baseToPivotLeg, *pivotToTargetLeg}, nil
+}
+
+func (c *ExchangeHandler) findLeg(fromCurrencyCode string, toCurrencyCode string, at *time.Time) (*exchange.Leg, error) {
+	exchangeRate, err := c.findExchangeRate(fromCurrencyCode, toCurrencyCode, at)
+
+	if exchangeRate != nil {
+		return &exchange.Leg{ExchangeRate: *exchangeRate}, nil
+	}
+	if !errors.Is(err, store.ExchangeRateNotFoundError) {
+		return nil, err
+	}
+
+	exchangeRate, err = c.findExchangeRate(toCurrencyCode, fromCurrencyCode, at)
+
+	if exchangeRate != nil {
+		return &exchange.Leg{ExchangeRate: *exchangeRate, Inverse: true}, nil
+	}
+
+	return nil, err
+}
+
+func (c *ExchangeHandler) findPath(baseCurrencyId int64, targetCurrencyId int64, strategy string, at *time.Time) (exchange.Path, error) {
+	var exchangeRates []model.ExchangeRate
+	var err error
+
+	if at != nil {
+		exchangeRates, err = c.exchangeRateStore.FindAllAt(*at)
+	} else {
+		exchangeRates, err = c.exchangeRateStore.FindAll()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	graph := exchange.NewGraph(exchangeRates)
+
+	var path exchange.Path
+	var found bool
+
+	switch strategy {
+	case bestRatePathStrategy:
+		path, found = graph.BestRatePath(baseCurrencyId, targetCurrencyId)
+	default:
+		path, found = graph.ShortestPath(baseCurrencyId, targetCurrencyId)
+	}
+
+	if !found {
+		return nil, store.ExchangeRateNotFoundError
+	}
+
+	return path, nil
+}
+
+func (c *ExchangeHandler) findFeeRule(baseCurrencyId int64, targetCurrencyId int64) (*model.FeeRule, error) {
+	feeRule, err := c.feeRuleStore.FindApplicable(baseCurrencyId, targetCurrencyId)
+
+	if errors.Is(err, store.FeeRuleNotFoundError) {
+		return nil, nil
+	}
+
+	return feeRule, err
+}
+
+func (c *ExchangeHandler) findExchangeRate(baseCurrencyCode string, targetCurrencyCode string, at *time.Time) (*model.ExchangeRate, error) {
+	if at != nil {
+		return c.exchangeRateStore.FindByCurrencyCodesAt(baseCurrencyCode, targetCurrencyCode, *at)
+	}
+	return c.exchangeRateStore.FindByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+}
+
+// exchangePair holds everything needed to convert between two currencies,
+// so that it can be resolved once and reused for many amounts
+type exchangePair struct {
+	baseCurrency   *model.Currency
+	targetCurrency *model.Currency
+	path           exchange.Path
+	pathCodes      []string
+	feeRule        *model.FeeRule
+}
+
+func (p *exchangePair) roundingMode(roundingMode model.RoundingMode) model.RoundingMode {
+	if len(roundingMode) == 0 {
+		roundingMode = p.targetCurrency.RoundingMode
+	}
+	if len(roundingMode) == 0 {
+		roundingMode = exchange.DefaultRoundingMode
+	}
+	return roundingMode
+}
+
+func (p *exchangePair) convert(amount decimal.Decimal, side model.Side, roundingMode model.RoundingMode) response.Exchange {
+	path := p.path.WithSide(side)
+
+	convertedAmount := path.Convert(amount, p.targetCurrency.MinorUnits, roundingMode)
+	fee := p.feeAmount(convertedAmount, roundingMode)
+
+	return response.Exchange{
+		BaseCurrency:    *p.baseCurrency,
+		TargetCurrency:  *p.targetCurrency,
+		Rate:            path.Rate(),
+		Amount:          amount,
+		ConvertedAmount: convertedAmount,
+		FeeAmount:       fee,
+		FeeCurrency:     p.targetCurrency.Code,
+		NetAmount:       convertedAmount.Sub(fee),
+		Path:            p.pathCodes,
+		Side:            side,
+		Spread:          path.Spread(),
+	}
+}
+
+func (p *exchangePair) feeAmount(convertedAmount decimal.Decimal, roundingMode model.RoundingMode) decimal.Decimal {
+	if p.feeRule == nil {
+		return decimal.Zero
+	}
+	return exchange.Fee(*p.feeRule, convertedAmount, p.targetCurrency.MinorUnits, roundingMode)
+}
+
+// checkStaleness tells whether any exchange rate of the path is stale at the
+// given time. With the reject policy stale rates are an error instead.
+func (p *exchangePair) checkStaleness(staleness exchange.Staleness, at time.Time) (bool, error) {
+	var staleCodePairs []string
+
+	for i, leg := range p.path {
+		codePair := p.pathCodes[i] + p.pathCodes[i+1]
+		if leg.Inverse {
+			codePair = p.pathCodes[i+1] + p.pathCodes[i]
+		}
+
+		if staleness.IsStale(codePair, leg.ExchangeRate, at) {
+			staleCodePairs = append(staleCodePairs, codePair)
+		}
+	}
+
+	if len(staleCodePairs) == 0 {
+		return false, nil
+	}
+
+	if staleness.Policy == exchange.StaleRatePolicyReject {
+		return true, fmt.Errorf("Exchange rates are stale: %s", strings.Join(staleCodePairs, ", "))
+	}
+
+	return true, nil
+}
+
+// explain describes how the converted amount was derived, leg by leg
+func (p *exchangePair) explain(amount decimal.Decimal, side model.Side, roundingMode model.RoundingMode) *response.Explanation {
+	path := p.path.WithSide(side)
+
+	explanation := response.Explanation{
+		Method: syntheticRate,
+		Rounding: response.ExplanationRounding{
+			Mode:          roundingMode,
+			MinorUnits:    p.targetCurrency.MinorUnits,
+			RoundedAmount: path.Convert(amount, p.targetCurrency.MinorUnits, roundingMode),
+		},
+	}
+
+	if len(path) == 1 && path[0].Inverse {
+		explanation.Method = inverseRate
+	} else if len(path) == 1 {
+		explanation.Method = directRate
+	}
+
+	for i, step := range path.Steps(amount) {
+		direction := directRate
+		if step.Leg.Inverse {
+			direction = inverseRate
+		}
+
+		explanation.Legs = append(explanation.Legs, response.ExplanationLeg{
+			ExchangeRateId: step.Leg.ExchangeRate.Id,
+			From:           p.pathCodes[i],
+			To:             p.pathCodes[i+1],
+			Direction:      direction,
+			Quote:          step.Leg.Quote(),
+			QuotedRate:     step.Quote,
+			Rate:           step.Rate,
+			PathRate:       step.PathRate,
+			Amount:         step.Amount,
+		})
+
+		explanation.Rounding.UnroundedAmount = step.Amount
+	}
+
+	if p.feeRule != nil {
+		explanation.FeeRuleId = &p.feeRule.Id
+	}
+
+	return &explanation
+}
+
+// sourceAmount returns the smallest amount in the base currency that leaves at
+// least the net amount in the target currency after conversion and fee
+func (p *exchangePair) sourceAmount(
+	netAmount decimal.Decimal,
+	side model.Side,
+	roundingMode model.RoundingMode,
+) (decimal.Decimal, error) {
+	path := p.path.WithSide(side)
+	grossAmount := netAmount
+
+	if p.feeRule != nil {
+		var err error
+		grossAmount, err = exchange.GrossAmount(*p.feeRule, netAmount, p.targetCurrency.MinorUnits, roundingMode)
+
+		if err != nil {
+			return decimal.Zero, err
+		}
+	}
+
+	amount := path.SourceAmount(grossAmount, p.baseCurrency.MinorUnits, p.targetCurrency.MinorUnits, roundingMode)
+
+	// Rounding the converted amount up may move it to a tier with a higher fee
+	unit := decimal.New(1, -p.baseCurrency.MinorUnits)
+
+	for i := 0; i < maxReverseAdjustments; i++ {
+		convertedAmount := path.Convert(amount, p.targetCurrency.MinorUnits, roundingMode)
+		fee := p.feeAmount(convertedAmount, roundingMode)
+
+		if convertedAmount.Sub(fee).GreaterThanOrEqual(netAmount) {
+			return amount, nil
+		}
+
+		amount = amount.Add(unit)
+	}
+
+	return decimal.Zero, errors.New("Net amount cannot be reached with the applicable fee rule")
 }
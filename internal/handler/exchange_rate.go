@@ -1,34 +1,57 @@
 package handler
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
 	"github.com/krios2146/currency-exchange-api-go/internal/response"
 	"github.com/krios2146/currency-exchange-api-go/internal/store"
 	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
 )
 
 type ExchangeRateHandler struct {
 	exchangeRateStore *store.ExchangeRateStore
 	currencyStore     *store.CurrencyStore
+	rateChangeStore   *store.RateChangeStore
+	staleness         exchange.Staleness
+	maxDeviation      decimal.Decimal
+	// Rate changes are proposed and applied only once approved
+	approvalRequired bool
 }
 
-func NewExchangeRateHandler(exchangeRateStore *store.ExchangeRateStore, currencyStore *store.CurrencyStore) *ExchangeRateHandler {
+func NewExchangeRateHandler(
+	exchangeRateStore *store.ExchangeRateStore,
+	currencyStore *store.CurrencyStore,
+	rateChangeStore *store.RateChangeStore,
+	staleness exchange.Staleness,
+	maxDeviation decimal.Decimal,
+	approvalRequired bool,
+) *ExchangeRateHandler {
 	return &ExchangeRateHandler{
 		exchangeRateStore: exchangeRateStore,
 		currencyStore:     currencyStore,
+		rateChangeStore:   rateChangeStore,
+		staleness:         staleness,
+		maxDeviation:      maxDeviation,
+		approvalRequired:  approvalRequired,
 	}
 }
 
 func (c *ExchangeRateHandler) GetAllExchangeRates(w http.ResponseWriter, r *http.Request) {
 	slog.Debug("GET /exchangeRates was called")
 
-	exchangeRates, err := c.exchangeRateStore.FindAll()
+	now := time.Now()
+	exchangeRates, err := c.exchangeRateStore.FindAllAt(now)
 
 	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
@@ -44,6 +67,51 @@ func (c *ExchangeRateHandler) GetAllExchangeRates(w http.ResponseWriter, r *http
 		targetCurrency, terr := c.currencyStore.FindById(exchangeRate.TargetCurrencyId)
 
 		if berr != nil || terr != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: errors.Join(berr, terr).Error()})
+			return
+		}
+
+		exchangeRateResponse := response.ExchangeRate{
+			Id:             exchangeRate.Id,
+			BaseCurrency:   *baseCurrency,
+			TargetCurrency: *targetCurrency,
+			Rate:           exchangeRate.Rate,
+			Bid:            exchange.Bid(exchangeRate),
+			Ask:            exchange.Ask(exchangeRate),
+			EffectiveFrom:  exchangeRate.EffectiveFrom,
+			Provider:       exchangeRate.Provider,
+			FetchedAt:      exchangeRate.FetchedAt,
+		}
+		c.addAge(&exchangeRateResponse, exchangeRate, now)
+		exchangeRateResponses = append(exchangeRateResponses, exchangeRateResponse)
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exchangeRateResponses)
+}
+
+func (c *ExchangeRateHandler) GetScheduledExchangeRates(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /exchangeRates/scheduled was called")
+
+	exchangeRates, err := c.exchangeRateStore.FindScheduled(time.Now())
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	exchangeRateResponses := []response.ExchangeRate{}
+
+	for _, exchangeRate := range exchangeRates {
+		baseCurrency, berr := c.currencyStore.FindById(exchangeRate.BaseCurrencyId)
+		targetCurrency, terr := c.currencyStore.FindById(exchangeRate.TargetCurrencyId)
+
+		if err := errors.Join(berr, terr); err != nil {
 			w.Header().Add("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
@@ -55,6 +123,11 @@ func (c *ExchangeRateHandler) GetAllExchangeRates(w http.ResponseWriter, r *http
 			BaseCurrency:   *baseCurrency,
 			TargetCurrency: *targetCurrency,
 			Rate:           exchangeRate.Rate,
+			Bid:            exchange.Bid(exchangeRate),
+			Ask:            exchange.Ask(exchangeRate),
+			EffectiveFrom:  exchangeRate.EffectiveFrom,
+			Provider:       exchangeRate.Provider,
+			FetchedAt:      exchangeRate.FetchedAt,
 		}
 		exchangeRateResponses = append(exchangeRateResponses, exchangeRateResponse)
 	}
@@ -78,6 +151,8 @@ func (c *ExchangeRateHandler) GetExchangeRateByCodes(w http.ResponseWriter, r *h
 
 	baseCurrencyCode := codePair[0:3]
 	targetCurrencyCode := codePair[3:6]
+	atStr := r.URL.Query().Get("at")
+	sourcesStr := r.URL.Query().Get("sources")
 
 	if err := validator.ValidateCurrencyCode(baseCurrencyCode); err != nil {
 		w.Header().Add("Content-Type", "application/json")
@@ -92,7 +167,35 @@ func (c *ExchangeRateHandler) GetExchangeRateByCodes(w http.ResponseWriter, r *h
 		return
 	}
 
-	exchangeRate, err := c.exchangeRateStore.FindByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+	at := time.Now()
+
+	if len(atStr) != 0 {
+		var err error
+		at, err = time.Parse(time.RFC3339, atStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse time from '%s'", atStr)})
+			return
+		}
+	}
+
+	withSources := false
+
+	if len(sourcesStr) != 0 {
+		var err error
+		withSources, err = strconv.ParseBool(sourcesStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse sources from '%s'", sourcesStr)})
+			return
+		}
+	}
+
+	exchangeRate, err := c.exchangeRateStore.FindByCurrencyCodesAt(baseCurrencyCode, targetCurrencyCode, at)
 
 	if errors.Is(err, store.ExchangeRateNotFoundError) {
 		w.Header().Add("Content-Type", "application/json")
@@ -114,7 +217,7 @@ func (c *ExchangeRateHandler) GetExchangeRateByCodes(w http.ResponseWriter, r *h
 	if berr != nil || terr != nil {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: errors.Join(berr, terr).Error()})
 		return
 	}
 
@@ -123,6 +226,21 @@ func (c *ExchangeRateHandler) GetExchangeRateByCodes(w http.ResponseWriter, r *h
 		BaseCurrency:   *baseCurrency,
 		TargetCurrency: *targetCurrency,
 		Rate:           exchangeRate.Rate,
+		Bid:            exchange.Bid(*exchangeRate),
+		Ask:            exchange.Ask(*exchangeRate),
+		EffectiveFrom:  exchangeRate.EffectiveFrom,
+		Provider:       exchangeRate.Provider,
+		FetchedAt:      exchangeRate.FetchedAt,
+	}
+	c.addAge(&exchangeRateResponse, *exchangeRate, at)
+
+	if withSources {
+		if err := c.addSources(&exchangeRateResponse, *exchangeRate); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
 	}
 
 	w.Header().Add("Content-Type", "application/json")
@@ -143,7 +261,7 @@ func (c *ExchangeRateHandler) AddExchangeRate(w http.ResponseWriter, r *http.Req
 	baseCurrencyCode := r.Form.Get("baseCurrencyCode")
 	targetCurrencyCode := r.Form.Get("targetCurrencyCode")
 	rateStr := r.Form.Get("rate")
-	rate, err := strconv.ParseFloat(rateStr, 64)
+	rate, err := decimal.NewFromString(rateStr)
 
 	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
@@ -165,13 +283,31 @@ func (c *ExchangeRateHandler) AddExchangeRate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if rate <= 0 {
+	if rate.Sign() <= 0 {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Rate cannot be negative or zero"})
 		return
 	}
 
+	spread, err := parseSpread(r.Form)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	effectiveFrom, err := parseEffectiveFrom(r.Form)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
 	baseCurrency, berr := c.currencyStore.FindByCode(baseCurrencyCode)
 	targetCurrency, terr := c.currencyStore.FindByCode(targetCurrencyCode)
 
@@ -200,12 +336,39 @@ func (c *ExchangeRateHandler) AddExchangeRate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	exchangeRate, err := c.exchangeRateStore.Save(baseCurrency.Id, targetCurrency.Id, rate)
+	if spread == nil {
+		spread = &model.Spread{}
+	}
+
+	if err := validator.ValidateSpread(rate, *spread); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if c.approvalRequired {
+		c.proposeRateChange(w, r, model.RateChange{
+			Type:               model.RateChangeTypeCreate,
+			BaseCurrencyId:     baseCurrency.Id,
+			TargetCurrencyId:   targetCurrency.Id,
+			BaseCurrencyCode:   baseCurrency.Code,
+			TargetCurrencyCode: targetCurrency.Code,
+			Rate:               rate,
+			Spread:             *spread,
+			EffectiveFrom:      proposedEffectiveFrom(r.Form, effectiveFrom),
+		})
+		return
+	}
+
+	exchangeRate, err := c.exchangeRateStore.Save(baseCurrency.Id, targetCurrency.Id, rate, *spread, effectiveFrom)
 
 	if errors.Is(err, store.ExchangeRateAlreadyExistsError) {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusConflict)
-		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: c.conflictMessage(baseCurrencyCode, targetCurrencyCode, err),
+		})
 		return
 	}
 
@@ -221,6 +384,11 @@ func (c *ExchangeRateHandler) AddExchangeRate(w http.ResponseWriter, r *http.Req
 		BaseCurrency:   *baseCurrency,
 		TargetCurrency: *targetCurrency,
 		Rate:           exchangeRate.Rate,
+		Bid:            exchange.Bid(*exchangeRate),
+		Ask:            exchange.Ask(*exchangeRate),
+		EffectiveFrom:  exchangeRate.EffectiveFrom,
+		Provider:       exchangeRate.Provider,
+		FetchedAt:      exchangeRate.FetchedAt,
 	}
 
 	w.Header().Add("Content-Type", "application/json")
@@ -264,7 +432,7 @@ func (c *ExchangeRateHandler) UpdateExchangeRate(w http.ResponseWriter, r *http.
 	}
 
 	rateStr := r.Form.Get("rate")
-	rate, err := strconv.ParseFloat(rateStr, 64)
+	rate, err := decimal.NewFromString(rateStr)
 
 	if err != nil {
 		w.Header().Add("Content-Type", "application/json")
@@ -273,13 +441,45 @@ func (c *ExchangeRateHandler) UpdateExchangeRate(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if rate <= 0 {
+	if rate.Sign() <= 0 {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Rate cannot be negative or zero"})
 		return
 	}
 
+	spread, err := parseSpread(r.Form)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	effectiveFrom, err := parseEffectiveFrom(r.Form)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	force := false
+	forceStr := r.Form.Get("force")
+
+	if len(forceStr) != 0 {
+		force, err = strconv.ParseBool(forceStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse force from '%s'", forceStr)})
+			return
+		}
+	}
+
 	baseCurrency, berr := c.currencyStore.FindByCode(baseCurrencyCode)
 	targetCurrency, terr := c.currencyStore.FindByCode(targetCurrencyCode)
 
@@ -308,7 +508,71 @@ func (c *ExchangeRateHandler) UpdateExchangeRate(w http.ResponseWriter, r *http.
 		return
 	}
 
-	exchangeRate, err := c.exchangeRateStore.Update(baseCurrency.Id, targetCurrency.Id, rate)
+	currentExchangeRate, err := c.exchangeRateStore.FindByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+
+	// A rate that isn't in effect yet is updated against the scheduled one
+	if errors.Is(err, store.ExchangeRateNotFoundError) {
+		currentExchangeRate, err = c.exchangeRateStore.FindLatestByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+	}
+
+	if err != nil && !errors.Is(err, store.ExchangeRateNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	// Spread is kept unchanged unless given
+	if spread == nil {
+		if currentExchangeRate == nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: store.ExchangeRateNotFoundError.Error()})
+			return
+		}
+
+		spread = &currentExchangeRate.Spread
+	}
+
+	// Guards against typos in the rate, e.g. 9.4 instead of 0.94
+	if currentExchangeRate != nil && !force && c.maxDeviation.IsPositive() {
+		deviation := exchange.Deviation(currentExchangeRate.Rate, rate)
+
+		if deviation.GreaterThan(c.maxDeviation) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{
+				Message: fmt.Sprintf(
+					"Rate %s deviates from the current rate %s by %s%%, more than the allowed %s%%. Use force to apply it anyway",
+					rate, currentExchangeRate.Rate, deviation.Round(2), c.maxDeviation,
+				),
+			})
+			return
+		}
+	}
+
+	if err := validator.ValidateSpread(rate, *spread); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if c.approvalRequired {
+		c.proposeRateChange(w, r, model.RateChange{
+			Type:               model.RateChangeTypeUpdate,
+			BaseCurrencyId:     baseCurrency.Id,
+			TargetCurrencyId:   targetCurrency.Id,
+			BaseCurrencyCode:   baseCurrency.Code,
+			TargetCurrencyCode: targetCurrency.Code,
+			Rate:               rate,
+			Spread:             *spread,
+			EffectiveFrom:      proposedEffectiveFrom(r.Form, effectiveFrom),
+		})
+		return
+	}
+
+	exchangeRate, err := c.exchangeRateStore.Update(baseCurrency.Id, targetCurrency.Id, rate, *spread, effectiveFrom)
 
 	if errors.Is(err, store.ExchangeRateNotFoundError) {
 		w.Header().Add("Content-Type", "application/json")
@@ -329,9 +593,458 @@ func (c *ExchangeRateHandler) UpdateExchangeRate(w http.ResponseWriter, r *http.
 		BaseCurrency:   *baseCurrency,
 		TargetCurrency: *targetCurrency,
 		Rate:           exchangeRate.Rate,
+		Bid:            exchange.Bid(*exchangeRate),
+		Ask:            exchange.Ask(*exchangeRate),
+		EffectiveFrom:  exchangeRate.EffectiveFrom,
+		Provider:       exchangeRate.Provider,
+		FetchedAt:      exchangeRate.FetchedAt,
 	}
 
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(exchangeRateResponse)
 }
+
+func (c *ExchangeRateHandler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	codePair := r.PathValue("code_pair")
+
+	slog.Debug("DELETE /exchangeRate/{code_pair} was called, with", "code_pair", codePair)
+
+	if len(codePair) != 6 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Code pair must contain exactly 6 letters"})
+		return
+	}
+
+	baseCurrencyCode := codePair[0:3]
+	targetCurrencyCode := codePair[3:6]
+
+	if err := errors.Join(
+		validator.ValidateCurrencyCode(baseCurrencyCode),
+		validator.ValidateCurrencyCode(targetCurrencyCode),
+	); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	baseCurrency, err := c.currencyStore.FindByCode(baseCurrencyCode)
+
+	var targetCurrency *model.Currency
+	var exchangeRate *model.ExchangeRate
+
+	if err == nil {
+		targetCurrency, err = c.currencyStore.FindByCode(targetCurrencyCode)
+	}
+
+	if err == nil && c.approvalRequired {
+		exchangeRate, err = c.exchangeRateStore.FindByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+
+		// A rate that isn't in effect yet is deleted as the scheduled one
+		if errors.Is(err, store.ExchangeRateNotFoundError) {
+			exchangeRate, err = c.exchangeRateStore.FindLatestByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+		}
+	}
+
+	if err == nil && !c.approvalRequired {
+		err = c.exchangeRateStore.Delete(baseCurrency.Id, targetCurrency.Id, time.Now())
+	}
+
+	if errors.Is(err, store.CurrencyNotFoundError) || errors.Is(err, store.ExchangeRateNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if c.approvalRequired {
+		c.proposeRateChange(w, r, model.RateChange{
+			Type:               model.RateChangeTypeDelete,
+			BaseCurrencyId:     baseCurrency.Id,
+			TargetCurrencyId:   targetCurrency.Id,
+			BaseCurrencyCode:   baseCurrency.Code,
+			TargetCurrencyCode: targetCurrency.Code,
+			Rate:               exchangeRate.Rate,
+			Spread:             exchangeRate.Spread,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *ExchangeRateHandler) GetExchangeRateSeries(w http.ResponseWriter, r *http.Request) {
+	codePair := r.PathValue("code_pair")
+
+	slog.Debug("GET /exchangeRate/{code_pair}/series was called, with", "code_pair", codePair)
+
+	if len(codePair) != 6 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Code pair must contain exactly 6 letters"})
+		return
+	}
+
+	query := r.URL.Query()
+
+	baseCurrencyCode := codePair[0:3]
+	targetCurrencyCode := codePair[3:6]
+	fromStr := query.Get("from")
+	toStr := query.Get("to")
+	intervalStr := query.Get("interval")
+	format := query.Get("format")
+
+	if err := validator.ValidateCurrencyCode(baseCurrencyCode); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err := validator.ValidateCurrencyCode(targetCurrencyCode); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, fromStr)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse time from '%s'", fromStr)})
+		return
+	}
+
+	to := time.Now()
+
+	if len(toStr) != 0 {
+		to, err = time.Parse(time.RFC3339, toStr)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse time from '%s'", toStr)})
+			return
+		}
+	}
+
+	if !from.Before(to) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Start of the series must be before its end"})
+		return
+	}
+
+	if len(intervalStr) == 0 {
+		intervalStr = "1d"
+	}
+
+	interval, err := exchange.ParseInterval(intervalStr)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if to.Sub(from)/interval >= exchange.MaxSeriesBuckets {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Series cannot contain more than %d buckets", exchange.MaxSeriesBuckets),
+		})
+		return
+	}
+
+	if format != "" && format != "json" && format != "csv" {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Format must be either 'json' or 'csv', got: %s", format),
+		})
+		return
+	}
+
+	baseCurrency, berr := c.currencyStore.FindByCode(baseCurrencyCode)
+	targetCurrency, terr := c.currencyStore.FindByCode(targetCurrencyCode)
+
+	if errors.Is(berr, store.CurrencyNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: berr.Error()})
+		return
+	}
+	if berr != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: berr.Error()})
+		return
+	}
+	if errors.Is(terr, store.CurrencyNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: terr.Error()})
+		return
+	}
+	if terr != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: terr.Error()})
+		return
+	}
+
+	initial, err := c.exchangeRateStore.FindByCurrencyCodesAt(baseCurrencyCode, targetCurrencyCode, from)
+
+	if err != nil && !errors.Is(err, store.ExchangeRateNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	history, err := c.exchangeRateStore.FindHistoryByCurrencyCodes(baseCurrencyCode, targetCurrencyCode, from, to)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if initial == nil && len(history) == 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: store.ExchangeRateNotFoundError.Error()})
+		return
+	}
+
+	buckets := exchange.Series(initial, history, from, to, interval)
+
+	if format == "csv" {
+		w.Header().Add("Content-Type", "text/csv")
+		w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", codePair))
+		w.WriteHeader(http.StatusOK)
+
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"start", "end", "open", "high", "low", "close", "count"})
+
+		for _, bucket := range buckets {
+			writer.Write([]string{
+				bucket.Start.Format(time.RFC3339),
+				bucket.End.Format(time.RFC3339),
+				bucket.Open.String(),
+				bucket.High.String(),
+				bucket.Low.String(),
+				bucket.Close.String(),
+				strconv.Itoa(bucket.Count),
+			})
+		}
+
+		writer.Flush()
+		return
+	}
+
+	seriesResponse := response.ExchangeRateSeries{
+		BaseCurrency:   *baseCurrency,
+		TargetCurrency: *targetCurrency,
+		Interval:       intervalStr,
+		Buckets:        []response.ExchangeRateBucket{},
+	}
+
+	for _, bucket := range buckets {
+		seriesResponse.Buckets = append(seriesResponse.Buckets, response.ExchangeRateBucket{
+			Start: bucket.Start,
+			End:   bucket.End,
+			Open:  bucket.Open,
+			High:  bucket.High,
+			Low:   bucket.Low,
+			Close: bucket.Close,
+			Count: bucket.Count,
+		})
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(seriesResponse)
+}
+
+// parseSpread returns the spread given either as bid and ask or as spreadBps,
+// or nil when the request contains neither
+func parseSpread(form url.Values) (*model.Spread, error) {
+	bidStr := form.Get("bid")
+	askStr := form.Get("ask")
+	spreadBpsStr := form.Get("spreadBps")
+
+	if len(bidStr) == 0 && len(askStr) == 0 && len(spreadBpsStr) == 0 {
+		return nil, nil
+	}
+
+	if len(spreadBpsStr) != 0 {
+		if len(bidStr) != 0 || len(askStr) != 0 {
+			return nil, errors.New("Spread must be given either as bid and ask or as spreadBps, not both")
+		}
+
+		spreadBps, err := decimal.NewFromString(spreadBpsStr)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Couldn't parse spreadBps from '%s'", spreadBpsStr))
+		}
+
+		return &model.Spread{SpreadBps: decimal.NewNullDecimal(spreadBps)}, nil
+	}
+
+	if len(bidStr) == 0 || len(askStr) == 0 {
+		return nil, errors.New("Both bid and ask must be present in the request")
+	}
+
+	bid, err := decimal.NewFromString(bidStr)
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Couldn't parse bid from '%s'", bidStr))
+	}
+
+	ask, err := decimal.NewFromString(askStr)
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Couldn't parse ask from '%s'", askStr))
+	}
+
+	return &model.Spread{Bid: decimal.NewNullDecimal(bid), Ask: decimal.NewNullDecimal(ask)}, nil
+}
+
+func (c *ExchangeRateHandler) proposeRateChange(w http.ResponseWriter, r *http.Request, rateChange model.RateChange) {
+	rateChange.ProposedBy = r.Header.Get(userHeader)
+	rateChange.ProposedAt = time.Now()
+
+	if len(rateChange.ProposedBy) == 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("%s header is required to propose rate changes", userHeader)})
+		return
+	}
+
+	proposed, err := c.rateChangeStore.Save(rateChange)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	rateChangeResponse, err := toRateChangeResponse(c.currencyStore, *proposed)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(rateChangeResponse)
+}
+
+// conflictMessage names the scheduled rate a new rate conflicts with, as it
+// isn't returned before it takes effect
+func (c *ExchangeRateHandler) conflictMessage(baseCurrencyCode string, targetCurrencyCode string, err error) string {
+	if _, cerr := c.exchangeRateStore.FindByCurrencyCodes(baseCurrencyCode, targetCurrencyCode); !errors.Is(cerr, store.ExchangeRateNotFoundError) {
+		return err.Error()
+	}
+
+	scheduled, serr := c.exchangeRateStore.FindLatestByCurrencyCodes(baseCurrencyCode, targetCurrencyCode)
+
+	if serr != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf(
+		"Exchange rate already exists and is scheduled to take effect at %s, update it instead",
+		scheduled.EffectiveFrom.Format(time.RFC3339),
+	)
+}
+
+// addAge adds how long the exchange rate has been in effect at the given time
+// and whether it is stale
+func (c *ExchangeRateHandler) addAge(exchangeRateResponse *response.ExchangeRate, exchangeRate model.ExchangeRate, at time.Time) {
+	codePair := exchangeRateResponse.BaseCurrency.Code + exchangeRateResponse.TargetCurrency.Code
+	age := int64(exchange.Age(exchangeRate, at).Seconds())
+
+	exchangeRateResponse.AgeSeconds = &age
+	exchangeRateResponse.Stale = c.staleness.IsStale(codePair, exchangeRate, at)
+}
+
+// addSources adds the provider quotes the exchange rate was aggregated from and
+// how far apart the accepted ones are
+func (c *ExchangeRateHandler) addSources(exchangeRateResponse *response.ExchangeRate, exchangeRate model.ExchangeRate) error {
+	sources, err := c.exchangeRateStore.FindSources(exchangeRate.HistoryId)
+
+	if err != nil {
+		return err
+	}
+
+	var accepted []exchange.SourceRate
+
+	for _, source := range sources {
+		exchangeRateResponse.Sources = append(exchangeRateResponse.Sources, response.RateSource{
+			Provider:  source.Provider,
+			Rate:      source.Rate,
+			FetchedAt: source.FetchedAt,
+			Rejected:  source.Rejected,
+		})
+
+		if !source.Rejected {
+			accepted = append(accepted, exchange.SourceRate{Source: source.Provider, Rate: source.Rate})
+		}
+	}
+
+	if len(accepted) != 0 {
+		sourceSpread := exchange.SourceSpread(exchangeRate.Rate, accepted)
+		exchangeRateResponse.SourceSpread = &sourceSpread
+	}
+
+	return nil
+}
+
+// parseEffectiveFrom returns the time the rate takes effect at, now unless a
+// future time is given
+func parseEffectiveFrom(form url.Values) (time.Time, error) {
+	now := time.Now()
+	effectiveFromStr := form.Get("effectiveFrom")
+
+	if len(effectiveFromStr) == 0 {
+		return now, nil
+	}
+
+	effectiveFrom, err := time.Parse(time.RFC3339, effectiveFromStr)
+
+	if err != nil {
+		return now, errors.New(fmt.Sprintf("Couldn't parse effectiveFrom from '%s'", effectiveFromStr))
+	}
+	if effectiveFrom.Before(now) {
+		return now, errors.New("Effective from time cannot be in the past")
+	}
+
+	return effectiveFrom, nil
+}
+
+// proposedEffectiveFrom returns the effective time of a proposed rate change,
+// nil when the change should take effect on approval
+func proposedEffectiveFrom(form url.Values, effectiveFrom time.Time) *time.Time {
+	if len(form.Get("effectiveFrom")) == 0 {
+		return nil
+	}
+	return &effectiveFrom
+}
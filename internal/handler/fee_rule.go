@@ -0,0 +1,391 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/krios2146/currency-exchange-api-go/internal/response"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type FeeRuleHandler struct {
+	feeRuleStore  *store.FeeRuleStore
+	currencyStore *store.CurrencyStore
+}
+
+func NewFeeRuleHandler(feeRuleStore *store.FeeRuleStore, currencyStore *store.CurrencyStore) *FeeRuleHandler {
+	return &FeeRuleHandler{
+		feeRuleStore:  feeRuleStore,
+		currencyStore: currencyStore,
+	}
+}
+
+func (c *FeeRuleHandler) GetAllFeeRules(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /feeRules was called")
+
+	feeRules, err := c.feeRuleStore.FindAll()
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	feeRuleResponses := []response.FeeRule{}
+
+	for _, feeRule := range feeRules {
+		feeRuleResponse, err := c.toResponse(feeRule)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+
+		feeRuleResponses = append(feeRuleResponses, *feeRuleResponse)
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(feeRuleResponses)
+}
+
+func (c *FeeRuleHandler) GetFeeRuleById(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	slog.Debug("GET /feeRule/{id} was called with", "id", idStr)
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse id from '%s'", idStr)})
+		return
+	}
+
+	feeRule, err := c.feeRuleStore.FindById(id)
+
+	if errors.Is(err, store.FeeRuleNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	feeRuleResponse, err := c.toResponse(*feeRule)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(feeRuleResponse)
+}
+
+func (c *FeeRuleHandler) AddFeeRule(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /feeRules was called")
+
+	if err := r.ParseForm(); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	feeRule := model.FeeRule{Scope: model.FeeScopeGlobal}
+
+	if status, err := c.applyForm(r.Form, &feeRule); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	savedFeeRule, err := c.feeRuleStore.Save(feeRule)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	feeRuleResponse, err := c.toResponse(*savedFeeRule)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(feeRuleResponse)
+}
+
+func (c *FeeRuleHandler) UpdateFeeRule(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	slog.Debug("PATCH /feeRule/{id} was called with", "id", idStr)
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse id from '%s'", idStr)})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	feeRule, err := c.feeRuleStore.FindById(id)
+
+	if errors.Is(err, store.FeeRuleNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if status, err := c.applyForm(r.Form, feeRule); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	updatedFeeRule, err := c.feeRuleStore.Update(*feeRule)
+
+	if errors.Is(err, store.FeeRuleNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	feeRuleResponse, err := c.toResponse(*updatedFeeRule)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(feeRuleResponse)
+}
+
+func (c *FeeRuleHandler) DeleteFeeRule(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+
+	slog.Debug("DELETE /feeRule/{id} was called with", "id", idStr)
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse id from '%s'", idStr)})
+		return
+	}
+
+	err = c.feeRuleStore.Delete(id)
+
+	if errors.Is(err, store.FeeRuleNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyForm overrides the fee rule with the fields present in the form and
+// validates the result. The returned status code describes the error.
+func (c *FeeRuleHandler) applyForm(form url.Values, feeRule *model.FeeRule) (int, error) {
+	if scope := form.Get("scope"); len(scope) != 0 {
+		feeRule.Scope = model.FeeScope(scope)
+	}
+	if feeType := form.Get("type"); len(feeType) != 0 {
+		feeRule.Type = model.FeeType(feeType)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value **int64
+	}{
+		{"currencyCode", &feeRule.CurrencyId},
+		{"baseCurrencyCode", &feeRule.BaseCurrencyId},
+		{"targetCurrencyCode", &feeRule.TargetCurrencyId},
+	} {
+		code := form.Get(field.name)
+
+		if len(code) == 0 {
+			continue
+		}
+
+		if err := validator.ValidateCurrencyCode(code); err != nil {
+			return http.StatusBadRequest, err
+		}
+
+		currency, err := c.currencyStore.FindByCode(code)
+
+		if errors.Is(err, store.CurrencyNotFoundError) {
+			return http.StatusNotFound, err
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		*field.value = &currency.Id
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *decimal.NullDecimal
+	}{
+		{"amount", &feeRule.Amount},
+		{"percentage", &feeRule.Percentage},
+	} {
+		valueStr := form.Get(field.name)
+
+		if len(valueStr) == 0 {
+			continue
+		}
+
+		value, err := decimal.NewFromString(valueStr)
+
+		if err != nil {
+			return http.StatusBadRequest, errors.New(fmt.Sprintf("Couldn't parse %s from '%s'", field.name, valueStr))
+		}
+
+		*field.value = decimal.NewNullDecimal(value)
+	}
+
+	if tiersStr := form.Get("tiers"); len(tiersStr) != 0 {
+		var tiers []model.FeeTier
+
+		if err := json.Unmarshal([]byte(tiersStr), &tiers); err != nil {
+			return http.StatusBadRequest, errors.New(fmt.Sprintf("Couldn't parse tiers from '%s'", tiersStr))
+		}
+
+		feeRule.Tiers = tiers
+	}
+
+	// Fields that don't belong to the scope or the type are dropped
+	switch feeRule.Scope {
+	case model.FeeScopeGlobal:
+		feeRule.CurrencyId, feeRule.BaseCurrencyId, feeRule.TargetCurrencyId = nil, nil, nil
+	case model.FeeScopeCurrency:
+		feeRule.BaseCurrencyId, feeRule.TargetCurrencyId = nil, nil
+	case model.FeeScopePair:
+		feeRule.CurrencyId = nil
+	}
+
+	switch feeRule.Type {
+	case model.FeeTypeFlat:
+		feeRule.Percentage, feeRule.Tiers = decimal.NullDecimal{}, nil
+	case model.FeeTypePercentage:
+		feeRule.Amount, feeRule.Tiers = decimal.NullDecimal{}, nil
+	case model.FeeTypeTiered:
+		feeRule.Amount, feeRule.Percentage = decimal.NullDecimal{}, decimal.NullDecimal{}
+	}
+
+	if err := validator.ValidateFeeRule(*feeRule); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	return 0, nil
+}
+
+func (c *FeeRuleHandler) toResponse(feeRule model.FeeRule) (*response.FeeRule, error) {
+	feeRuleResponse := response.FeeRule{
+		Id:    feeRule.Id,
+		Scope: feeRule.Scope,
+		Type:  feeRule.Type,
+		Tiers: feeRule.Tiers,
+	}
+
+	if feeRule.Amount.Valid {
+		feeRuleResponse.Amount = &feeRule.Amount.Decimal
+	}
+	if feeRule.Percentage.Valid {
+		feeRuleResponse.Percentage = &feeRule.Percentage.Decimal
+	}
+
+	for _, field := range []struct {
+		id       *int64
+		currency **model.Currency
+	}{
+		{feeRule.CurrencyId, &feeRuleResponse.Currency},
+		{feeRule.BaseCurrencyId, &feeRuleResponse.BaseCurrency},
+		{feeRule.TargetCurrencyId, &feeRuleResponse.TargetCurrency},
+	} {
+		if field.id == nil {
+			continue
+		}
+
+		currency, err := c.currencyStore.FindById(*field.id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		*field.currency = currency
+	}
+
+	return &feeRuleResponse, nil
+}
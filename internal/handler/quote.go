@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/krios2146/currency-exchange-api-go/internal/response"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type QuoteHandler struct {
+	quoteStore      *store.QuoteStore
+	currencyStore   *store.CurrencyStore
+	exchangeHandler *ExchangeHandler
+	ttl             time.Duration
+}
+
+func NewQuoteHandler(
+	quoteStore *store.QuoteStore,
+	currencyStore *store.CurrencyStore,
+	exchangeHandler *ExchangeHandler,
+	ttl time.Duration,
+) *QuoteHandler {
+	return &QuoteHandler{
+		quoteStore:      quoteStore,
+		currencyStore:   currencyStore,
+		exchangeHandler: exchangeHandler,
+		ttl:             ttl,
+	}
+}
+
+func (c *QuoteHandler) AddQuote(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /quotes was called")
+
+	if err := r.ParseForm(); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	baseCurrencyCode := r.Form.Get("from")
+	targetCurrencyCode := r.Form.Get("to")
+	amountStr := r.Form.Get("amount")
+	targetAmountStr := r.Form.Get("toAmount")
+	roundingMode := r.Form.Get("rounding")
+	side := r.Form.Get("side")
+
+	reverse := len(targetAmountStr) != 0
+
+	if reverse && len(amountStr) != 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Either amount or toAmount must be present, not both"})
+		return
+	}
+
+	if reverse {
+		amountStr = targetAmountStr
+	}
+
+	amount, err := decimal.NewFromString(amountStr)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Couldn't parse amount from '%s'", amountStr),
+		})
+		return
+	}
+	if amount.IsNegative() {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: "Amount cannot be negative"})
+		return
+	}
+	if err := validator.ValidateCurrencyCode(baseCurrencyCode); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err := validator.ValidateCurrencyCode(targetCurrencyCode); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if len(roundingMode) != 0 {
+		if err := validator.ValidateRoundingMode(roundingMode); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	if len(side) != 0 {
+		if err := validator.ValidateSide(side); err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	pair, err := newPairResolver(c.exchangeHandler).resolve(baseCurrencyCode, targetCurrencyCode)
+
+	if errors.Is(err, store.CurrencyNotFoundError) || errors.Is(err, store.ExchangeRateNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	stale, err := pair.checkStaleness(c.exchangeHandler.staleness, time.Now())
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	mode := pair.roundingMode(model.RoundingMode(roundingMode))
+
+	if reverse {
+		amount, err = pair.sourceAmount(amount, model.Side(side), mode)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	exchange := pair.convert(amount, model.Side(side), mode)
+	exchange.Stale = stale
+	now := time.Now()
+
+	quote, err := c.quoteStore.Save(model.Quote{
+		BaseCurrencyId:   exchange.BaseCurrency.Id,
+		TargetCurrencyId: exchange.TargetCurrency.Id,
+		Rate:             exchange.Rate,
+		Amount:           exchange.Amount,
+		ConvertedAmount:  exchange.ConvertedAmount,
+		FeeAmount:        exchange.FeeAmount,
+		NetAmount:        exchange.NetAmount,
+		Spread:           exchange.Spread,
+		Side:             exchange.Side,
+		Path:             exchange.Path,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(c.ttl),
+	})
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(&response.Quote{
+		Id:        quote.Id,
+		Exchange:  exchange,
+		ExpiresAt: quote.ExpiresAt,
+	})
+}
+
+func (c *QuoteHandler) AcceptQuote(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /quotes/{id}/accept was called")
+
+	quote, err := c.quoteStore.Accept(r.PathValue("id"), time.Now())
+
+	if errors.Is(err, store.QuoteNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, store.QuoteExpiredError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, store.QuoteAlreadyAcceptedError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	quoteResponse, err := c.toResponse(quote)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(quoteResponse)
+}
+
+func (c *QuoteHandler) toResponse(quote *model.Quote) (*response.Quote, error) {
+	baseCurrency, err := findCurrencyOrCode(c.currencyStore, quote.BaseCurrencyId, quote.Path[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	targetCurrency, err := findCurrencyOrCode(c.currencyStore, quote.TargetCurrencyId, quote.Path[len(quote.Path)-1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Quote{
+		Id: quote.Id,
+		Exchange: response.Exchange{
+			BaseCurrency:    *baseCurrency,
+			TargetCurrency:  *targetCurrency,
+			Rate:            quote.Rate,
+			Amount:          quote.Amount,
+			ConvertedAmount: quote.ConvertedAmount,
+			FeeAmount:       quote.FeeAmount,
+			FeeCurrency:     targetCurrency.Code,
+			NetAmount:       quote.NetAmount,
+			Path:            quote.Path,
+			Side:            quote.Side,
+			Spread:          quote.Spread,
+		},
+		ExpiresAt:  quote.ExpiresAt,
+		AcceptedAt: quote.AcceptedAt,
+	}, nil
+}
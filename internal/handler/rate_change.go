@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/krios2146/currency-exchange-api-go/internal/response"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+)
+
+// Header identifying the user who proposes or reviews a rate change
+const userHeader = "X-User"
+
+type RateChangeHandler struct {
+	rateChangeStore *store.RateChangeStore
+	currencyStore   *store.CurrencyStore
+}
+
+func NewRateChangeHandler(rateChangeStore *store.RateChangeStore, currencyStore *store.CurrencyStore) *RateChangeHandler {
+	return &RateChangeHandler{
+		rateChangeStore: rateChangeStore,
+		currencyStore:   currencyStore,
+	}
+}
+
+func (c *RateChangeHandler) GetAllRateChanges(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /rateChanges was called")
+
+	status := model.RateChangeStatus(r.URL.Query().Get("status"))
+
+	if len(status) != 0 && !slices.Contains(model.RateChangeStatuses, status) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{
+			Message: fmt.Sprintf("Status must be one of %v, got: %s", model.RateChangeStatuses, status),
+		})
+		return
+	}
+
+	rateChanges, err := c.rateChangeStore.FindAll(status)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	rateChangeResponses := []response.RateChange{}
+
+	for _, rateChange := range rateChanges {
+		rateChangeResponse, err := toRateChangeResponse(c.currencyStore, rateChange)
+
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+			return
+		}
+
+		rateChangeResponses = append(rateChangeResponses, *rateChangeResponse)
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rateChangeResponses)
+}
+
+func (c *RateChangeHandler) GetRateChangeById(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /rateChange/{id} was called")
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse id from '%s'", r.PathValue("id"))})
+		return
+	}
+
+	rateChange, err := c.rateChangeStore.FindById(id)
+
+	if errors.Is(err, store.RateChangeNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	rateChangeResponse, err := toRateChangeResponse(c.currencyStore, *rateChange)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rateChangeResponse)
+}
+
+func (c *RateChangeHandler) ApproveRateChange(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /rateChange/{id}/approve was called")
+
+	c.review(w, r, c.rateChangeStore.Approve)
+}
+
+func (c *RateChangeHandler) RejectRateChange(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("POST /rateChange/{id}/reject was called")
+
+	c.review(w, r, c.rateChangeStore.Reject)
+}
+
+func (c *RateChangeHandler) review(
+	w http.ResponseWriter,
+	r *http.Request,
+	review func(id int64, reviewer string, at time.Time) (*model.RateChange, error),
+) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("Couldn't parse id from '%s'", r.PathValue("id"))})
+		return
+	}
+
+	reviewer := r.Header.Get(userHeader)
+
+	if len(reviewer) == 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: fmt.Sprintf("%s header is required to review rate changes", userHeader)})
+		return
+	}
+
+	rateChange, err := review(id, reviewer, time.Now())
+
+	if errors.Is(err, store.RateChangeNotFoundError) ||
+		errors.Is(err, store.ExchangeRateNotFoundError) ||
+		errors.Is(err, store.CurrencyNotFoundError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, store.RateChangeSelfReviewError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, store.RateChangeAlreadyReviewedError) || errors.Is(err, store.ExchangeRateAlreadyExistsError) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	rateChangeResponse, err := toRateChangeResponse(c.currencyStore, *rateChange)
+
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&response.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rateChangeResponse)
+}
+
+func toRateChangeResponse(currencyStore *store.CurrencyStore, rateChange model.RateChange) (*response.RateChange, error) {
+	baseCurrency, err := findCurrencyOrCode(currencyStore, rateChange.BaseCurrencyId, rateChange.BaseCurrencyCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	targetCurrency, err := findCurrencyOrCode(currencyStore, rateChange.TargetCurrencyId, rateChange.TargetCurrencyCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rateChangeResponse := response.RateChange{
+		Id:             rateChange.Id,
+		Type:           rateChange.Type,
+		BaseCurrency:   *baseCurrency,
+		TargetCurrency: *targetCurrency,
+		Rate:           rateChange.Rate,
+		EffectiveFrom:  rateChange.EffectiveFrom,
+		Status:         rateChange.Status,
+		ProposedBy:     rateChange.ProposedBy,
+		ProposedAt:     rateChange.ProposedAt,
+		ReviewedBy:     rateChange.ReviewedBy,
+		ReviewedAt:     rateChange.ReviewedAt,
+		ExchangeRateId: rateChange.ExchangeRateId,
+	}
+
+	if rateChange.Bid.Valid {
+		rateChangeResponse.Bid = &rateChange.Bid.Decimal
+	}
+	if rateChange.Ask.Valid {
+		rateChangeResponse.Ask = &rateChange.Ask.Decimal
+	}
+	if rateChange.SpreadBps.Valid {
+		rateChangeResponse.SpreadBps = &rateChange.SpreadBps.Decimal
+	}
+
+	return &rateChangeResponse, nil
+}
+
+// findCurrencyOrCode returns the currency, or one named after its code when it
+// was deleted since the record referencing it was made
+func findCurrencyOrCode(currencyStore *store.CurrencyStore, id int64, code string) (*model.Currency, error) {
+	currency, err := currencyStore.FindById(id)
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		return &model.Currency{Id: id, Code: code, FullName: code, Sign: code}, nil
+	}
+
+	return currency, err
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/response"
+)
+
+type RateProviderHandler struct {
+	providers []*provider.ResilientProvider
+}
+
+func NewRateProviderHandler(providers []*provider.ResilientProvider) *RateProviderHandler {
+	return &RateProviderHandler{
+		providers: providers,
+	}
+}
+
+func (h *RateProviderHandler) GetAllRateProviders(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("GET /rateProviders was called")
+
+	statuses := make([]response.RateProviderStatus, 0, len(h.providers))
+
+	for _, rateProvider := range h.providers {
+		status := rateProvider.Status()
+
+		statuses = append(statuses, response.RateProviderStatus{
+			Name:                status.Name,
+			State:               string(status.State),
+			ConsecutiveFailures: status.ConsecutiveFailures,
+			LastSuccess:         status.LastSuccess,
+			LastError:           status.LastError,
+			LastErrorAt:         status.LastErrorAt,
+			OpenUntil:           status.OpenUntil,
+		})
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(statuses)
+}
@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/iso4217"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// ECBProviderName is recorded as the provider of the imported rates
+const ECBProviderName = "ecb"
+
+// ECBImport is the outcome of an import of the ECB reference rates
+type ECBImport struct {
+	From              time.Time
+	To                time.Time
+	Days              int
+	Imported          int
+	Skipped           int
+	CreatedCurrencies []model.Currency
+}
+
+// ECBImporter imports the ECB reference rates as EUR based exchange rates,
+// each day taking effect at its date. Missing currencies are created. A rate
+// deviating from the previous day's rate, or from the rate in effect at its
+// date, by more than the max deviation is skipped.
+type ECBImporter struct {
+	exchangeRateStore *store.ExchangeRateStore
+	currencyStore     *store.CurrencyStore
+	maxDeviation      decimal.Decimal
+}
+
+func NewECBImporter(
+	exchangeRateStore *store.ExchangeRateStore,
+	currencyStore *store.CurrencyStore,
+	maxDeviation decimal.Decimal,
+) *ECBImporter {
+	return &ECBImporter{
+		exchangeRateStore: exchangeRateStore,
+		currencyStore:     currencyStore,
+		maxDeviation:      maxDeviation,
+	}
+}
+
+func (i *ECBImporter) Import(days []provider.ECBDay) (*ECBImport, error) {
+	if len(days) == 0 {
+		return &ECBImport{}, nil
+	}
+
+	result := ECBImport{
+		From: days[0].Date,
+		To:   days[len(days)-1].Date,
+		Days: len(days),
+	}
+
+	currencies := make(map[string]*model.Currency)
+	previousRates := make(map[string]decimal.Decimal)
+	importedAt := time.Now()
+
+	var exchangeRates []model.ExchangeRate
+
+	for _, day := range days {
+		for _, quote := range day.Quotes {
+			if err := validator.ValidateCurrencyCode(quote.TargetCurrencyCode); err != nil {
+				slog.Warn("Skipping ECB rate with invalid currency code", "date", day.Date, "error", err)
+				result.Skipped++
+				continue
+			}
+			if quote.Rate.Sign() <= 0 || quote.TargetCurrencyCode == quote.BaseCurrencyCode {
+				slog.Warn("Skipping invalid ECB rate", "date", day.Date, "code", quote.TargetCurrencyCode)
+				result.Skipped++
+				continue
+			}
+
+			deviates, err := i.deviates(previousRates, day.Date, quote)
+
+			if err != nil {
+				return nil, err
+			}
+			if deviates {
+				result.Skipped++
+				continue
+			}
+
+			baseCurrency, err := i.findOrCreateCurrency(currencies, quote.BaseCurrencyCode, &result)
+
+			if err != nil {
+				return nil, err
+			}
+
+			targetCurrency, err := i.findOrCreateCurrency(currencies, quote.TargetCurrencyCode, &result)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if baseCurrency == nil || targetCurrency == nil {
+				result.Skipped++
+				continue
+			}
+
+			exchangeRates = append(exchangeRates, model.ExchangeRate{
+				BaseCurrencyId:   baseCurrency.Id,
+				TargetCurrencyId: targetCurrency.Id,
+				Rate:             quote.Rate,
+				EffectiveFrom:    day.Date,
+				Provider:         ECBProviderName,
+				FetchedAt:        &importedAt,
+			})
+		}
+	}
+
+	imported, err := i.exchangeRateStore.Import(exchangeRates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = imported
+	result.Skipped += len(exchangeRates) - imported
+
+	slog.Info("ECB rates imported", "days", result.Days, "imported", result.Imported, "skipped", result.Skipped)
+
+	return &result, nil
+}
+
+// deviates compares the rate with the previous day's rate of the pair, or with
+// the rate in effect at its date for the first day of the pair, and records it
+// as the previous rate unless it deviates
+func (i *ECBImporter) deviates(previousRates map[string]decimal.Decimal, date time.Time, quote provider.Quote) (bool, error) {
+	if !i.maxDeviation.IsPositive() {
+		return false, nil
+	}
+
+	codePair := quote.BaseCurrencyCode + quote.TargetCurrencyCode
+	previous, exists := previousRates[codePair]
+
+	if !exists {
+		current, err := i.exchangeRateStore.FindByCurrencyCodesAt(quote.BaseCurrencyCode, quote.TargetCurrencyCode, date)
+
+		if err != nil && !errors.Is(err, store.ExchangeRateNotFoundError) {
+			return false, err
+		}
+
+		// Nothing to compare the first rate of a new pair with
+		if current == nil {
+			previousRates[codePair] = quote.Rate
+			return false, nil
+		}
+
+		previous = current.Rate
+	}
+
+	if deviation := exchange.Deviation(previous, quote.Rate); deviation.GreaterThan(i.maxDeviation) {
+		slog.Warn(
+			"Skipping ECB rate deviating from the previous rate",
+			"date", date, "code", quote.TargetCurrencyCode, "rate", quote.Rate, "previous", previous, "deviation", deviation,
+		)
+		previousRates[codePair] = previous
+		return true, nil
+	}
+
+	previousRates[codePair] = quote.Rate
+
+	return false, nil
+}
+
+// findOrCreateCurrency returns nil for a missing currency that can't be
+// created, as its code isn't in the ISO 4217 catalog
+func (i *ECBImporter) findOrCreateCurrency(
+	currencies map[string]*model.Currency,
+	code string,
+	result *ECBImport,
+) (*model.Currency, error) {
+	if currency, exists := currencies[code]; exists {
+		return currency, nil
+	}
+
+	currency, err := i.currencyStore.FindByCode(code)
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		if verr := validator.ValidateNewCurrencyCode(code); verr != nil {
+			slog.Warn("Skipping ECB rates of a currency that can't be created", "error", verr)
+			currencies[code] = nil
+			return nil, nil
+		}
+
+		entry, known := iso4217.Lookup(code)
+
+		if !known {
+			entry = iso4217.Currency{Code: code, Name: code, Sign: code, MinorUnits: 2}
+		}
+
+		currency, err = i.currencyStore.Save(entry.Name, code, entry.Sign, entry.MinorUnits, "")
+
+		if err == nil {
+			slog.Info("Currency created by the ECB import", "code", code)
+			result.CreatedCurrencies = append(result.CreatedCurrencies, *currency)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	currencies[code] = currency
+
+	return currency, nil
+}
@@ -0,0 +1,79 @@
+package iso4217
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// Currency is an active currency of the ISO 4217 list
+type Currency struct {
+	Code string
+	// Three digits, with leading zeros, e.g. 008
+	Numeric    string
+	MinorUnits int32
+	Name       string
+	// Commonly used symbol, it isn't a part of the standard
+	Sign      string
+	Countries []string
+}
+
+// currencies.csv holds the active currencies without funds, precious metals
+// and testing codes
+//
+//go:embed currencies.csv
+var data string
+
+var currencies, codes = parse(data)
+
+// Lookup returns the catalog entry of the alphabetic code
+func Lookup(code string) (Currency, bool) {
+	currency, exists := currencies[code]
+
+	return currency, exists
+}
+
+// All returns every currency of the catalog ordered by code
+func All() []Currency {
+	all := make([]Currency, 0, len(codes))
+
+	for _, code := range codes {
+		all = append(all, currencies[code])
+	}
+
+	return all
+}
+
+// parse panics on a malformed catalog since it's embedded at build time
+func parse(data string) (map[string]Currency, []string) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+
+	if err != nil {
+		panic("iso4217: couldn't read catalog: " + err.Error())
+	}
+
+	currencies := make(map[string]Currency, len(records))
+	var codes []string
+
+	// The first record is the header
+	for _, record := range records[1:] {
+		minorUnits, err := strconv.ParseInt(record[2], 10, 32)
+
+		if err != nil {
+			panic("iso4217: couldn't parse minor units of " + record[0])
+		}
+
+		currencies[record[0]] = Currency{
+			Code:       record[0],
+			Numeric:    record[1],
+			MinorUnits: int32(minorUnits),
+			Name:       record[3],
+			Sign:       record[4],
+			Countries:  strings.Split(record[5], ";"),
+		}
+		codes = append(codes, record[0])
+	}
+
+	return currencies, codes
+}
@@ -0,0 +1,76 @@
+package job
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// ConsistencyCheck periodically looks for cycles of exchange rates whose
+// product deviates from 1 and logs them.
+type ConsistencyCheck struct {
+	exchangeRateStore *store.ExchangeRateStore
+	currencyStore     *store.CurrencyStore
+	threshold         decimal.Decimal
+}
+
+func NewConsistencyCheck(
+	exchangeRateStore *store.ExchangeRateStore,
+	currencyStore *store.CurrencyStore,
+	threshold decimal.Decimal,
+) *ConsistencyCheck {
+	return &ConsistencyCheck{
+		exchangeRateStore: exchangeRateStore,
+		currencyStore:     currencyStore,
+		threshold:         threshold,
+	}
+}
+
+func (c *ConsistencyCheck) Start(interval time.Duration) {
+	if interval == 0 {
+		slog.Info("Consistency check is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			c.Run()
+			<-ticker.C
+		}
+	}()
+}
+
+func (c *ConsistencyCheck) Run() {
+	slog.Debug("Running consistency check")
+
+	exchangeRates, err := c.exchangeRateStore.FindAll()
+
+	if err != nil {
+		slog.Error("Consistency check failed", "error", err)
+		return
+	}
+
+	inconsistencies := exchange.FindInconsistencies(exchange.NewGraph(exchangeRates), c.threshold)
+
+	for _, inconsistency := range inconsistencies {
+		path, err := inconsistency.Cycle.Codes(c.currencyStore.FindById)
+
+		if err != nil {
+			slog.Error("Consistency check failed", "error", err)
+			return
+		}
+
+		slog.Warn(
+			"Inconsistent exchange rates",
+			"path", path,
+			"product", inconsistency.Product,
+			"deviation", inconsistency.Deviation,
+		)
+	}
+}
@@ -0,0 +1,281 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	"github.com/krios2146/currency-exchange-api-go/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// RateIngestion periodically pulls exchange rates from the rate providers and
+// upserts them. A provider that fails is skipped, so the rates it quoted
+// before are kept. When several providers quote the same pair their rates are
+// aggregated, providers listed first having the higher priority. Quotes of
+// unknown currencies are skipped, as are aggregated rates deviating from the
+// current rate by more than the max deviation.
+type RateIngestion struct {
+	providers         []provider.RateProvider
+	exchangeRateStore *store.ExchangeRateStore
+	currencyStore     *store.CurrencyStore
+	aggregator        exchange.Aggregator
+	maxDeviation      decimal.Decimal
+}
+
+// sourceQuote is a quote along with the provider it was fetched from
+type sourceQuote struct {
+	provider.Quote
+	providerName string
+	priority     int
+	fetchedAt    time.Time
+}
+
+func NewRateIngestion(
+	providers []provider.RateProvider,
+	exchangeRateStore *store.ExchangeRateStore,
+	currencyStore *store.CurrencyStore,
+	aggregator exchange.Aggregator,
+	maxDeviation decimal.Decimal,
+) *RateIngestion {
+	return &RateIngestion{
+		providers:         providers,
+		exchangeRateStore: exchangeRateStore,
+		currencyStore:     currencyStore,
+		aggregator:        aggregator,
+		maxDeviation:      maxDeviation,
+	}
+}
+
+func (i *RateIngestion) Start(interval time.Duration) {
+	if len(i.providers) == 0 || interval == 0 {
+		slog.Info("Rate ingestion is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			i.Run()
+			<-ticker.C
+		}
+	}()
+}
+
+// Run pulls the rates of every provider, then aggregates and upserts them pair
+// by pair
+func (i *RateIngestion) Run() {
+	var pairs []string
+	quotesByPair := make(map[string][]sourceQuote)
+
+	for priority, rateProvider := range i.providers {
+		quotes, err := i.fetch(rateProvider)
+
+		if errors.Is(err, provider.CircuitOpenError) {
+			slog.Warn("Skipping rate provider", "provider", rateProvider.Name(), "error", err)
+			continue
+		}
+		if err != nil {
+			slog.Error("Rate ingestion failed", "provider", rateProvider.Name(), "error", err)
+			continue
+		}
+
+		for _, quote := range quotes {
+			if !isValidQuote(rateProvider.Name(), quote.Quote) {
+				continue
+			}
+
+			quote.priority = priority
+			codePair := quote.BaseCurrencyCode + quote.TargetCurrencyCode
+
+			if _, exists := quotesByPair[codePair]; !exists {
+				pairs = append(pairs, codePair)
+			}
+
+			quotesByPair[codePair] = append(quotesByPair[codePair], quote)
+		}
+	}
+
+	upserted := 0
+
+	for _, codePair := range pairs {
+		ok, err := i.upsert(quotesByPair[codePair])
+
+		if err != nil {
+			slog.Error("Rate ingestion failed", "pair", codePair, "error", err)
+			continue
+		}
+		if ok {
+			upserted++
+		}
+	}
+
+	slog.Info("Exchange rates ingested", "pairs", len(pairs), "upserted", upserted)
+}
+
+func (i *RateIngestion) fetch(rateProvider provider.RateProvider) ([]sourceQuote, error) {
+	slog.Debug("Fetching exchange rates", "provider", rateProvider.Name())
+
+	quotes, err := rateProvider.FetchRates(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedAt := time.Now()
+	sourceQuotes := make([]sourceQuote, 0, len(quotes))
+
+	for _, quote := range quotes {
+		sourceQuotes = append(sourceQuotes, sourceQuote{
+			Quote:        quote,
+			providerName: rateProvider.Name(),
+			fetchedAt:    fetchedAt,
+		})
+	}
+
+	slog.Debug("Exchange rates fetched", "provider", rateProvider.Name(), "fetched", len(quotes))
+
+	return sourceQuotes, nil
+}
+
+func isValidQuote(providerName string, quote provider.Quote) bool {
+	if err := errors.Join(
+		validator.ValidateCurrencyCode(quote.BaseCurrencyCode),
+		validator.ValidateCurrencyCode(quote.TargetCurrencyCode),
+	); err != nil {
+		slog.Warn("Skipping quote with invalid currency code", "provider", providerName, "error", err)
+		return false
+	}
+	if quote.Rate.Sign() <= 0 || quote.BaseCurrencyCode == quote.TargetCurrencyCode {
+		slog.Warn("Skipping invalid quote", "provider", providerName, "base", quote.BaseCurrencyCode, "target", quote.TargetCurrencyCode)
+		return false
+	}
+	return true
+}
+
+// upsert aggregates the quotes of a single pair, which come in the order of
+// priority. The spread of the accepted quote with the highest priority is kept
+// if it's valid for the aggregated rate.
+func (i *RateIngestion) upsert(quotes []sourceQuote) (bool, error) {
+	baseCode := quotes[0].BaseCurrencyCode
+	targetCode := quotes[0].TargetCurrencyCode
+
+	baseCurrency, err := i.currencyStore.FindByCode(baseCode)
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		slog.Debug("Skipping quote of unknown currency", "code", baseCode)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	targetCurrency, err := i.currencyStore.FindByCode(targetCode)
+
+	if errors.Is(err, store.CurrencyNotFoundError) {
+		slog.Debug("Skipping quote of unknown currency", "code", targetCode)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	sourceRates := make([]exchange.SourceRate, 0, len(quotes))
+
+	for _, quote := range quotes {
+		sourceRates = append(sourceRates, exchange.SourceRate{
+			Source:   quote.providerName,
+			Rate:     quote.Rate,
+			Priority: quote.priority,
+		})
+	}
+
+	rate, sourceRates, ok := i.aggregator.Aggregate(sourceRates)
+
+	var sources []model.RateSource
+	var accepted []sourceQuote
+
+	for j, sourceRate := range sourceRates {
+		sources = append(sources, model.RateSource{
+			Provider:  sourceRate.Source,
+			Rate:      sourceRate.Rate,
+			FetchedAt: quotes[j].fetchedAt,
+			Rejected:  sourceRate.Rejected,
+		})
+
+		if sourceRate.Rejected {
+			slog.Warn("Rejecting outlier quote", "provider", sourceRate.Source, "base", baseCode, "target", targetCode, "rate", sourceRate.Rate)
+		} else {
+			accepted = append(accepted, quotes[j])
+		}
+	}
+
+	if !ok {
+		slog.Warn("Skipping pair without consensus among rate providers", "base", baseCode, "target", targetCode)
+		return false, nil
+	}
+
+	// Guards against provider glitches the same way updates are guarded
+	// against typos
+	if i.maxDeviation.IsPositive() {
+		current, err := i.exchangeRateStore.FindByCurrencyCodes(baseCode, targetCode)
+
+		if err != nil && !errors.Is(err, store.ExchangeRateNotFoundError) {
+			return false, err
+		}
+
+		if current != nil {
+			if deviation := exchange.Deviation(current.Rate, rate); deviation.GreaterThan(i.maxDeviation) {
+				slog.Warn(
+					"Skipping aggregated rate deviating from the current rate",
+					"base", baseCode, "target", targetCode, "rate", rate, "current", current.Rate, "deviation", deviation,
+				)
+				return false, nil
+			}
+		}
+	}
+
+	var providerNames []string
+	spread := accepted[0].Spread
+	fetchedAt := accepted[0].fetchedAt
+
+	// Only the top priority provider contributes to a priority pick
+	if i.aggregator.Method == exchange.AggregationPriority {
+		accepted = accepted[:1]
+	}
+
+	for _, quote := range accepted {
+		if !slices.Contains(providerNames, quote.providerName) {
+			providerNames = append(providerNames, quote.providerName)
+		}
+		if quote.fetchedAt.After(fetchedAt) {
+			fetchedAt = quote.fetchedAt
+		}
+	}
+
+	if err := validator.ValidateSpread(rate, spread); err != nil {
+		slog.Warn("Dropping invalid spread of aggregated quote", "provider", accepted[0].providerName, "error", err)
+		spread = model.Spread{}
+	}
+
+	_, err = i.exchangeRateStore.Upsert(
+		baseCurrency.Id,
+		targetCurrency.Id,
+		rate,
+		spread,
+		strings.Join(providerNames, ","),
+		fetchedAt,
+		sources,
+	)
+
+	return err == nil, err
+}
@@ -0,0 +1,209 @@
+package job
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/exchange"
+	"github.com/krios2146/currency-exchange-api-go/internal/provider"
+	"github.com/krios2146/currency-exchange-api-go/internal/store"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+// newTestDB creates a database with the tables of the migrations and the
+// default currencies
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "database"))
+
+	if err != nil {
+		t.Fatalf("Couldn't open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob("../migration/create-*.sql")
+
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("Couldn't find migrations: %v", err)
+	}
+
+	for _, migration := range append(migrations, "../migration/fill-currencies-table.sql") {
+		script, err := os.ReadFile(migration)
+
+		if err != nil {
+			t.Fatalf("Couldn't read %s: %s", migration, err)
+		}
+		if _, err := db.Exec(string(script)); err != nil {
+			t.Fatalf("Couldn't apply %s: %s", migration, err)
+		}
+	}
+
+	return db
+}
+
+// newStubProvider serves the JSON body, or fails with the status when it isn't
+// 200
+func newStubProvider(t *testing.T, name string, status int, body string) provider.RateProvider {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return provider.NewJSONProvider(name, server.URL, server.Client())
+}
+
+func newTestIngestion(db *sql.DB, providers []provider.RateProvider, maxDeviation string) *RateIngestion {
+	return NewRateIngestion(
+		providers,
+		store.NewExchangeRateStore(db),
+		store.NewCurrencyStore(db),
+		exchange.Aggregator{Method: exchange.AggregationMedian},
+		decimal.RequireFromString(maxDeviation),
+	)
+}
+
+func TestRateIngestionRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers func(t *testing.T) []provider.RateProvider
+		wantRate  string
+		wantFrom  string
+		sources   int
+	}{
+		{
+			name: "single provider",
+			providers: func(t *testing.T) []provider.RateProvider {
+				return []provider.RateProvider{
+					newStubProvider(t, "first", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.92", "XYZ": "1"}}`),
+				}
+			},
+			wantRate: "0.92",
+			wantFrom: "first",
+			sources:  1,
+		},
+		{
+			name: "failing provider is skipped",
+			providers: func(t *testing.T) []provider.RateProvider {
+				return []provider.RateProvider{
+					newStubProvider(t, "failing", http.StatusInternalServerError, ""),
+					newStubProvider(t, "second", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.93"}}`),
+				}
+			},
+			wantRate: "0.93",
+			wantFrom: "second",
+			sources:  1,
+		},
+		{
+			name: "malformed provider is skipped",
+			providers: func(t *testing.T) []provider.RateProvider {
+				return []provider.RateProvider{
+					newStubProvider(t, "first", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.92", "XYZ": "1"}}`),
+					newStubProvider(t, "malformed", http.StatusOK, `{"base": "USD", "ra`),
+				}
+			},
+			wantRate: "0.92",
+			wantFrom: "first",
+			sources:  1,
+		},
+		{
+			name: "median of two providers",
+			providers: func(t *testing.T) []provider.RateProvider {
+				return []provider.RateProvider{
+					newStubProvider(t, "first", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.90"}}`),
+					newStubProvider(t, "second", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.94"}}`),
+				}
+			},
+			wantRate: "0.92",
+			wantFrom: "first,second",
+			sources:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			exchangeRateStore := store.NewExchangeRateStore(db)
+
+			newTestIngestion(db, tt.providers(t), "0").Run()
+
+			exchangeRate, err := exchangeRateStore.FindByCurrencyCodes("USD", "EUR")
+
+			if err != nil {
+				t.Fatalf("Ingested rate not found: %s", err)
+			}
+			if !exchangeRate.Rate.Equal(decimal.RequireFromString(tt.wantRate)) {
+				t.Errorf("Rate = %s, want %s", exchangeRate.Rate, tt.wantRate)
+			}
+			if exchangeRate.Provider != tt.wantFrom {
+				t.Errorf("Provider = %s, want %s", exchangeRate.Provider, tt.wantFrom)
+			}
+			if exchangeRate.FetchedAt == nil {
+				t.Error("FetchedAt isn't recorded")
+			}
+
+			sources, err := exchangeRateStore.FindSources(exchangeRate.HistoryId)
+
+			if err != nil {
+				t.Fatalf("Sources not found: %s", err)
+			}
+			if len(sources) != tt.sources {
+				t.Errorf("Got %d sources, want %d", len(sources), tt.sources)
+			}
+		})
+	}
+}
+
+func TestRateIngestionRunUpdates(t *testing.T) {
+	db := newTestDB(t)
+	exchangeRateStore := store.NewExchangeRateStore(db)
+
+	newTestIngestion(db, []provider.RateProvider{
+		newStubProvider(t, "stub", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.92"}}`),
+	}, "5").Run()
+
+	newTestIngestion(db, []provider.RateProvider{
+		newStubProvider(t, "stub", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.93"}}`),
+	}, "5").Run()
+
+	exchangeRate, err := exchangeRateStore.FindByCurrencyCodes("USD", "EUR")
+
+	if err != nil {
+		t.Fatalf("Ingested rate not found: %s", err)
+	}
+	if !exchangeRate.Rate.Equal(decimal.RequireFromString("0.93")) {
+		t.Errorf("Rate = %s, want 0.93", exchangeRate.Rate)
+	}
+}
+
+func TestRateIngestionRunSkipsDeviatingRate(t *testing.T) {
+	db := newTestDB(t)
+	exchangeRateStore := store.NewExchangeRateStore(db)
+
+	newTestIngestion(db, []provider.RateProvider{
+		newStubProvider(t, "stub", http.StatusOK, `{"base": "USD", "rates": {"EUR": "0.92"}}`),
+	}, "5").Run()
+
+	// 9.2 instead of 0.92
+	newTestIngestion(db, []provider.RateProvider{
+		newStubProvider(t, "stub", http.StatusOK, `{"base": "USD", "rates": {"EUR": "9.2"}}`),
+	}, "5").Run()
+
+	exchangeRate, err := exchangeRateStore.FindByCurrencyCodes("USD", "EUR")
+
+	if err != nil {
+		t.Fatalf("Ingested rate not found: %s", err)
+	}
+	if !exchangeRate.Rate.Equal(decimal.RequireFromString("0.92")) {
+		t.Errorf("Rate = %s, want the deviating rate to be skipped", exchangeRate.Rate)
+	}
+}
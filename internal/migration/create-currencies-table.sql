@@ -1,8 +1,11 @@
 CREATE TABLE IF NOT EXISTS Currencies (
-    id          INTEGER PRIMARY KEY,
-    code        varchar UNIQUE NOT NULL,
-    full_name   varchar NOT NULL,
-    sign        varchar NOT NULL,
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    code            varchar UNIQUE NOT NULL,
+    full_name       varchar NOT NULL,
+    sign            varchar NOT NULL,
+    minor_units     INTEGER NOT NULL DEFAULT 2,
+    rounding_mode   varchar NOT NULL DEFAULT '',
 
-    CHECK (length(code) == 3)
+    CHECK (length(code) == 3),
+    CHECK (minor_units BETWEEN 0 AND 18)
 );
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS Exchange_rate_sources (
+    id          INTEGER PRIMARY KEY,
+    history_id  INTEGER NOT NULL,
+    provider    varchar NOT NULL,
+    rate        varchar NOT NULL,
+    fetched_at  varchar NOT NULL,
+    rejected    INTEGER NOT NULL DEFAULT 0,
+
+    FOREIGN KEY(history_id) REFERENCES Exchange_rates_history(id)
+);
+
+CREATE INDEX IF NOT EXISTS Exchange_rate_sources_history_idx
+    ON Exchange_rate_sources (history_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS Exchange_rates_history (
+    id                  INTEGER PRIMARY KEY,
+    exchange_rate_id    INTEGER NOT NULL,
+    base_currency_id    INTEGER NOT NULL,
+    target_currency_id  INTEGER NOT NULL,
+    rate                varchar NOT NULL,
+    bid                 varchar,
+    ask                 varchar,
+    spread_bps          varchar,
+    effective_from      varchar NOT NULL,
+    provider            varchar,
+    fetched_at          varchar,
+    removed             INTEGER NOT NULL DEFAULT 0,
+
+    FOREIGN KEY(base_currency_id) REFERENCES Currencies(id),
+    FOREIGN KEY(target_currency_id) REFERENCES Currencies(id)
+);
+
+CREATE INDEX IF NOT EXISTS Exchange_rates_history_pair_idx
+    ON Exchange_rates_history (base_currency_id, target_currency_id, effective_from);
@@ -2,7 +2,10 @@ CREATE TABLE IF NOT EXISTS Exchange_rates (
     id                  INTEGER PRIMARY KEY,
     base_currency_id    varchar NOT NULL,
     target_currency_id  varchar NOT NULL,
-    rate                real NOT NULL,
+    rate                varchar NOT NULL,
+    bid                 varchar,
+    ask                 varchar,
+    spread_bps          varchar,
 
     UNIQUE(base_currency_id, target_currency_id),
     FOREIGN KEY(base_currency_id) REFERENCES Currencies(id),
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS Fee_rules (
+    id                  INTEGER PRIMARY KEY,
+    scope               varchar NOT NULL,
+    currency_id         INTEGER,
+    base_currency_id    INTEGER,
+    target_currency_id  INTEGER,
+    type                varchar NOT NULL,
+    amount              varchar,
+    percentage          varchar,
+    tiers               varchar,
+
+    CHECK (scope IN ('global', 'currency', 'pair')),
+    CHECK (type IN ('flat', 'percentage', 'tiered')),
+    FOREIGN KEY(currency_id) REFERENCES Currencies(id),
+    FOREIGN KEY(base_currency_id) REFERENCES Currencies(id),
+    FOREIGN KEY(target_currency_id) REFERENCES Currencies(id)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS Quotes (
+    id                  varchar PRIMARY KEY,
+    base_currency_id    INTEGER NOT NULL,
+    target_currency_id  INTEGER NOT NULL,
+    rate                varchar NOT NULL,
+    amount              varchar NOT NULL,
+    converted_amount    varchar NOT NULL,
+    fee_amount          varchar NOT NULL,
+    net_amount          varchar NOT NULL,
+    spread              varchar NOT NULL,
+    side                varchar NOT NULL DEFAULT '',
+    path                varchar NOT NULL,
+    created_at          varchar NOT NULL,
+    expires_at          varchar NOT NULL,
+    accepted_at         varchar,
+
+    FOREIGN KEY(base_currency_id) REFERENCES Currencies(id),
+    FOREIGN KEY(target_currency_id) REFERENCES Currencies(id)
+);
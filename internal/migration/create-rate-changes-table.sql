@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS Rate_changes (
+    id                  INTEGER PRIMARY KEY,
+    type                varchar NOT NULL,
+    base_currency_id    INTEGER NOT NULL,
+    target_currency_id  INTEGER NOT NULL,
+    base_currency_code  varchar NOT NULL,
+    target_currency_code varchar NOT NULL,
+    rate                varchar NOT NULL,
+    bid                 varchar,
+    ask                 varchar,
+    spread_bps          varchar,
+    effective_from      varchar,
+    status              varchar NOT NULL DEFAULT 'pending',
+    proposed_by         varchar NOT NULL,
+    proposed_at         varchar NOT NULL,
+    reviewed_by         varchar,
+    reviewed_at         varchar,
+    exchange_rate_id    INTEGER,
+
+    CHECK (type IN ('create', 'update', 'delete')),
+    CHECK (status IN ('pending', 'approved', 'rejected')),
+    FOREIGN KEY(base_currency_id) REFERENCES Currencies(id),
+    FOREIGN KEY(target_currency_id) REFERENCES Currencies(id),
+    FOREIGN KEY(exchange_rate_id) REFERENCES Exchange_rates(id)
+);
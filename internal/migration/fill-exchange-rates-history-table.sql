@@ -0,0 +1,3 @@
+INSERT INTO Exchange_rates_history (exchange_rate_id, base_currency_id, target_currency_id, rate, effective_from)
+SELECT id, base_currency_id, target_currency_id, rate, strftime('%Y-%m-%dT%H:%M:%S.000000000Z', 'now')
+FROM Exchange_rates;
@@ -1,5 +1,5 @@
-INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 2, 0.94);
-INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 3, 63.75);
-INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 4, 36.95);
-INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 5, 469.88);
-INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 6, 0.81);
+INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 2, '0.94');
+INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 3, '63.75');
+INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 4, '36.95');
+INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 5, '469.88');
+INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) values (1, 6, '0.81');
@@ -1,8 +1,10 @@
 package model
 
 type Currency struct {
-	Id       int64  `json:"id"`
-	Code     string `json:"code"`
-	FullName string `json:"name"`
-	Sign     string `json:"sign"`
+	Id           int64        `json:"id"`
+	Code         string       `json:"code"`
+	FullName     string       `json:"name"`
+	Sign         string       `json:"sign"`
+	MinorUnits   int32        `json:"minorUnits"`
+	RoundingMode RoundingMode `json:"roundingMode,omitempty"`
 }
@@ -1,8 +1,32 @@
 package model
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type ExchangeRate struct {
 	Id               int64
 	BaseCurrencyId   int64
 	TargetCurrencyId int64
-	Rate             float64
+	Rate             decimal.Decimal
+	Spread
+	EffectiveFrom time.Time
+	// Rate provider the rate was fetched from and when, empty for rates
+	// entered manually
+	Provider  string
+	FetchedAt *time.Time
+	// Id of the history entry the rate was read from or recorded as
+	HistoryId int64
+	// Quotes of the rate providers an aggregated rate was computed from
+	Sources []RateSource
+}
+
+// Spread is either absolute bid and ask rates or a markup in basis points
+// around the mid rate. Without both the bid and ask equal the mid rate.
+type Spread struct {
+	Bid       decimal.NullDecimal
+	Ask       decimal.NullDecimal
+	SpreadBps decimal.NullDecimal
 }
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ExchangeRateHistory struct {
+	Id               int64
+	ExchangeRateId   int64
+	BaseCurrencyId   int64
+	TargetCurrencyId int64
+	Rate             decimal.Decimal
+	EffectiveFrom    time.Time
+	// The rate was deleted at EffectiveFrom, Rate is the one it had
+	Removed bool
+}
@@ -0,0 +1,42 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+type FeeScope string
+
+const (
+	FeeScopeGlobal   FeeScope = "global"
+	FeeScopeCurrency FeeScope = "currency"
+	FeeScopePair     FeeScope = "pair"
+)
+
+type FeeType string
+
+const (
+	FeeTypeFlat       FeeType = "flat"
+	FeeTypePercentage FeeType = "percentage"
+	FeeTypeTiered     FeeType = "tiered"
+)
+
+// FeeRule is charged in the target currency of the exchange. A currency scoped
+// rule applies to exchanges from or to its currency, a pair scoped rule only to
+// exchanges from the base to the target currency.
+type FeeRule struct {
+	Id               int64
+	Scope            FeeScope
+	CurrencyId       *int64
+	BaseCurrencyId   *int64
+	TargetCurrencyId *int64
+	Type             FeeType
+	Amount           decimal.NullDecimal
+	Percentage       decimal.NullDecimal
+	Tiers            []FeeTier
+}
+
+// FeeTier applies to converted amounts up to and including UpTo, the last tier
+// may be unbounded. Both the flat amount and the percentage are optional.
+type FeeTier struct {
+	UpTo       decimal.NullDecimal `json:"upTo"`
+	Amount     decimal.NullDecimal `json:"amount"`
+	Percentage decimal.NullDecimal `json:"percentage"`
+}
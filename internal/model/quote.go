@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Quote struct {
+	Id               string
+	BaseCurrencyId   int64
+	TargetCurrencyId int64
+	Rate             decimal.Decimal
+	Amount           decimal.Decimal
+	ConvertedAmount  decimal.Decimal
+	FeeAmount        decimal.Decimal
+	NetAmount        decimal.Decimal
+	Spread           decimal.Decimal
+	Side             Side
+	Path             []string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	AcceptedAt       *time.Time
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type RateChangeType string
+
+const (
+	RateChangeTypeCreate RateChangeType = "create"
+	RateChangeTypeUpdate RateChangeType = "update"
+	RateChangeTypeDelete RateChangeType = "delete"
+)
+
+type RateChangeStatus string
+
+const (
+	RateChangeStatusPending  RateChangeStatus = "pending"
+	RateChangeStatusApproved RateChangeStatus = "approved"
+	RateChangeStatusRejected RateChangeStatus = "rejected"
+)
+
+var RateChangeStatuses = []RateChangeStatus{RateChangeStatusPending, RateChangeStatusApproved, RateChangeStatusRejected}
+
+// RateChange is a proposed creation, update or deletion of an exchange rate,
+// that is applied only once a different user approves it. Without an effective
+// time the change takes effect on approval. A deletion keeps the rate it
+// removes.
+type RateChange struct {
+	Id               int64
+	Type             RateChangeType
+	BaseCurrencyId   int64
+	TargetCurrencyId int64
+	// Codes of the currencies, kept for the record when they're deleted
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	Spread
+	EffectiveFrom  *time.Time
+	Status         RateChangeStatus
+	ProposedBy     string
+	ProposedAt     time.Time
+	ReviewedBy     *string
+	ReviewedAt     *time.Time
+	ExchangeRateId *int64
+}
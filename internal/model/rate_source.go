@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateSource is a rate quoted by a rate provider for an aggregated exchange
+// rate, kept whether or not it was rejected as an outlier
+type RateSource struct {
+	Provider  string
+	Rate      decimal.Decimal
+	FetchedAt time.Time
+	Rejected  bool
+}
@@ -0,0 +1,17 @@
+package model
+
+type RoundingMode string
+
+const (
+	RoundingModeHalfEven RoundingMode = "halfEven"
+	RoundingModeHalfUp   RoundingMode = "halfUp"
+	RoundingModeFloor    RoundingMode = "floor"
+	RoundingModeCeiling  RoundingMode = "ceiling"
+)
+
+var RoundingModes = []RoundingMode{
+	RoundingModeHalfEven,
+	RoundingModeHalfUp,
+	RoundingModeFloor,
+	RoundingModeCeiling,
+}
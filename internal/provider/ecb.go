@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ECBBaseCurrencyCode is the base currency of the ECB euro foreign exchange
+// reference rates
+const ECBBaseCurrencyCode = "EUR"
+
+// ECBDay is the reference rates published by the ECB for a single day
+type ECBDay struct {
+	Date   time.Time
+	Quotes []Quote
+}
+
+// ecbEnvelope is the format of eurofxref-daily.xml and eurofxref-hist.xml, a
+// Cube per day wrapped in a single Cube
+//
+//	<Cube><Cube time="2024-10-17"><Cube currency="USD" rate="1.0833"/></Cube></Cube>
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string          `xml:"currency,attr"`
+			Rate     decimal.Decimal `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB parses the ECB reference rates XML. Days are returned from the
+// oldest to the latest.
+func ParseECB(r io.Reader) ([]ECBDay, error) {
+	var envelope ecbEnvelope
+
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("Couldn't decode ECB rates: %w", err)
+	}
+
+	var days []ECBDay
+
+	for _, cube := range envelope.Days {
+		date, err := time.Parse(time.DateOnly, cube.Time)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ECB rates date %q", cube.Time)
+		}
+
+		day := ECBDay{Date: date}
+
+		for _, rate := range cube.Rates {
+			day.Quotes = append(day.Quotes, Quote{
+				BaseCurrencyCode:   ECBBaseCurrencyCode,
+				TargetCurrencyCode: rate.Currency,
+				Rate:               rate.Rate,
+			})
+		}
+
+		days = append(days, day)
+	}
+
+	if len(days) == 0 {
+		return nil, errors.New("No ECB rates found")
+	}
+
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+
+	return days, nil
+}
+
+// FetchECB downloads and parses the ECB reference rates XML
+func FetchECB(ctx context.Context, client *http.Client, url string) ([]ECBDay, error) {
+	resp, err := get(ctx, client, url)
+
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return ParseECB(resp.Body)
+}
+
+// ECBProvider fetches the latest day of the ECB reference rates, e.g. from
+// https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml
+type ECBProvider struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func NewECBProvider(name string, url string, client *http.Client) *ECBProvider {
+	return &ECBProvider{
+		name:   name,
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *ECBProvider) Name() string {
+	return p.name
+}
+
+func (p *ECBProvider) FetchRates(ctx context.Context) ([]Quote, error) {
+	days, err := FetchECB(ctx, p.client, p.url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return days[len(days)-1].Quotes, nil
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// JSONProvider fetches rates from an HTTP endpoint that responds with a base
+// currency and the rates of other currencies against it, e.g.
+//
+//	{"base": "USD", "rates": {"EUR": 0.9, "GBP": "0.81"}}
+type JSONProvider struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+type jsonRates struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+func NewJSONProvider(name string, url string, client *http.Client) *JSONProvider {
+	return &JSONProvider{
+		name:   name,
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *JSONProvider) Name() string {
+	return p.name
+}
+
+func (p *JSONProvider) FetchRates(ctx context.Context) ([]Quote, error) {
+	resp, err := get(ctx, p.client, p.url)
+
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rates jsonRates
+
+	if err := json.NewDecoder(resp.Body).Decode(&rates); err != nil {
+		return nil, fmt.Errorf("Couldn't decode rates: %w", err)
+	}
+
+	var quotes []Quote
+
+	for code, rate := range rates.Rates {
+		quotes = append(quotes, Quote{
+			BaseCurrencyCode:   rates.Base,
+			TargetCurrencyCode: code,
+			Rate:               rate,
+		})
+	}
+
+	return quotes, nil
+}
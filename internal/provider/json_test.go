@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestJSONProviderFetchRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "rates",
+			status: http.StatusOK,
+			body:   `{"base": "USD", "rates": {"EUR": 0.92, "GBP": "0.79"}}`,
+			want:   map[string]string{"EUR": "0.92", "GBP": "0.79"},
+		},
+		{
+			name:   "empty rates",
+			status: http.StatusOK,
+			body:   `{"base": "USD", "rates": {}}`,
+			want:   map[string]string{},
+		},
+		{
+			name:    "non-200 status",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"base": "USD", "rates": {"EUR": 0.92}}`,
+			wantErr: "Unexpected response status 503",
+		},
+		{
+			name:    "malformed JSON",
+			status:  http.StatusOK,
+			body:    `{"base": "USD", "rates": {"EUR": 0.9`,
+			wantErr: "Couldn't decode rates",
+		},
+		{
+			name:    "malformed rate",
+			status:  http.StatusOK,
+			body:    `{"base": "USD", "rates": {"EUR": "abc"}}`,
+			wantErr: "Couldn't decode rates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			jsonProvider := NewJSONProvider("stub", server.URL, server.Client())
+
+			quotes, err := jsonProvider.FetchRates(context.Background())
+
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FetchRates() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchRates() failed: %s", err)
+			}
+
+			if len(quotes) != len(tt.want) {
+				t.Fatalf("FetchRates() returned %d quotes, want %d", len(quotes), len(tt.want))
+			}
+
+			for _, quote := range quotes {
+				want, exists := tt.want[quote.TargetCurrencyCode]
+
+				if !exists || quote.BaseCurrencyCode != "USD" || !quote.Rate.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("Unexpected quote %s%s %s", quote.BaseCurrencyCode, quote.TargetCurrencyCode, quote.Rate)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONProviderFetchRatesCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewJSONProvider("stub", server.URL, server.Client()).FetchRates(ctx); err == nil {
+		t.Error("FetchRates() with a cancelled context succeeded")
+	}
+}
+
+func TestNew(t *testing.T) {
+	client := http.DefaultClient
+
+	if _, ok := New(FormatJSON, "json", "http://localhost", client).(*JSONProvider); !ok {
+		t.Error("New(json) isn't a JSONProvider")
+	}
+	if _, ok := New(FormatECB, "ecb", "http://localhost", client).(*ECBProvider); !ok {
+		t.Error("New(ecb) isn't an ECBProvider")
+	}
+	if !slices.Contains(Formats, FormatJSON) || !slices.Contains(Formats, FormatECB) {
+		t.Errorf("Formats = %v, want json and ecb", Formats)
+	}
+}
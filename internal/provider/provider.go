@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// Quote is a single exchange rate fetched from a rate provider
+type Quote struct {
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	Spread             model.Spread
+}
+
+// RateProvider is an external source of exchange rates that is pulled
+// periodically
+type RateProvider interface {
+	Name() string
+	FetchRates(ctx context.Context) ([]Quote, error)
+}
+
+// get requests the URL and fails on any status but 200 OK. The caller has to
+// close the response body.
+func get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Unexpected response status %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// Format is the response format of a rate provider
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatECB  Format = "ecb"
+)
+
+var Formats = []Format{FormatJSON, FormatECB}
+
+// New creates a rate provider that understands the given response format
+func New(format Format, name string, url string, client *http.Client) RateProvider {
+	if format == FormatECB {
+		return NewECBProvider(name, url, client)
+	}
+	return NewJSONProvider(name, url, client)
+}
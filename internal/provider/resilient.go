@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var CircuitOpenError error = errors.New("Circuit breaker is open")
+
+type CircuitState string
+
+const (
+	// Fetches go through
+	CircuitClosed CircuitState = "closed"
+	// Fetches fail right away until the cooldown is over
+	CircuitOpen CircuitState = "open"
+	// A single fetch is let through after the cooldown to probe the provider
+	CircuitHalfOpen CircuitState = "halfOpen"
+)
+
+// Resilience is the retry policy and the circuit breaker of a rate provider
+type Resilience struct {
+	// Attempts of a single fetch, including the first one
+	MaxAttempts int
+	// Delay before the first retry, doubled for each following one and
+	// jittered
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Consecutive failed fetches after which the circuit opens
+	FailureThreshold int
+	// How long the circuit stays open before a fetch is let through
+	Cooldown time.Duration
+}
+
+// Status is the health of a rate provider
+type Status struct {
+	Name                string
+	State               CircuitState
+	ConsecutiveFailures int
+	LastSuccess         *time.Time
+	LastError           string
+	LastErrorAt         *time.Time
+	OpenUntil           *time.Time
+}
+
+// ResilientProvider retries failed fetches of a rate provider with exponential
+// backoff and stops calling it for a cooldown after repeated failures. A fetch
+// without any rates is a failure as well.
+type ResilientProvider struct {
+	RateProvider
+	resilience Resilience
+
+	mu     sync.Mutex
+	status Status
+}
+
+func NewResilientProvider(rateProvider RateProvider, resilience Resilience) *ResilientProvider {
+	return &ResilientProvider{
+		RateProvider: rateProvider,
+		resilience:   resilience,
+		status: Status{
+			Name:  rateProvider.Name(),
+			State: CircuitClosed,
+		},
+	}
+}
+
+func (p *ResilientProvider) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.status
+}
+
+func (p *ResilientProvider) FetchRates(ctx context.Context) ([]Quote, error) {
+	maxAttempts, err := p.begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []Quote
+
+	for attempt := 1; ; attempt++ {
+		quotes, err = p.RateProvider.FetchRates(ctx)
+
+		if err == nil && len(quotes) == 0 {
+			err = errors.New("No rates in response")
+		}
+		if err == nil || attempt >= maxAttempts {
+			break
+		}
+
+		delay := p.backoff(attempt)
+
+		slog.Warn("Rate provider fetch failed, retrying", "provider", p.Name(), "attempt", attempt, "delay", delay, "error", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	p.end(err)
+
+	return quotes, err
+}
+
+// begin fails while the circuit is open and tells how many attempts the fetch
+// may make
+func (p *ResilientProvider) begin() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.status.State == CircuitOpen {
+		if time.Now().Before(*p.status.OpenUntil) {
+			return 0, fmt.Errorf("%w until %s", CircuitOpenError, p.status.OpenUntil.Format(time.RFC3339))
+		}
+
+		p.status.State = CircuitHalfOpen
+		p.status.OpenUntil = nil
+	}
+
+	if p.status.State == CircuitHalfOpen {
+		return 1, nil
+	}
+
+	return max(p.resilience.MaxAttempts, 1), nil
+}
+
+// end records the outcome of the fetch and opens or closes the circuit
+func (p *ResilientProvider) end(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	if err == nil {
+		if p.status.State != CircuitClosed {
+			slog.Info("Rate provider circuit closed", "provider", p.Name())
+		}
+
+		p.status.State = CircuitClosed
+		p.status.ConsecutiveFailures = 0
+		p.status.LastSuccess = &now
+		return
+	}
+
+	p.status.ConsecutiveFailures++
+	p.status.LastError = err.Error()
+	p.status.LastErrorAt = &now
+
+	if p.status.State == CircuitHalfOpen || p.status.ConsecutiveFailures >= p.resilience.FailureThreshold {
+		openUntil := now.Add(p.resilience.Cooldown)
+
+		slog.Warn("Rate provider circuit opened", "provider", p.Name(), "failures", p.status.ConsecutiveFailures, "until", openUntil)
+
+		p.status.State = CircuitOpen
+		p.status.OpenUntil = &openUntil
+	}
+}
+
+// backoff returns the delay before the retry following the attempt, somewhere
+// between half and all of the exponentially growing backoff
+func (p *ResilientProvider) backoff(attempt int) time.Duration {
+	delay := p.resilience.Backoff << (attempt - 1)
+
+	if delay <= 0 || delay > p.resilience.MaxBackoff {
+		delay = p.resilience.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,10 @@
+package response
+
+type CatalogCurrency struct {
+	Code       string   `json:"code"`
+	Numeric    string   `json:"numeric"`
+	Name       string   `json:"name"`
+	Sign       string   `json:"sign"`
+	MinorUnits int32    `json:"minorUnits"`
+	Countries  []string `json:"countries"`
+}
@@ -0,0 +1,15 @@
+package response
+
+import "github.com/shopspring/decimal"
+
+type ConsistencyReport struct {
+	Threshold       decimal.Decimal `json:"threshold"`
+	Inconsistencies []Inconsistency `json:"inconsistencies"`
+}
+
+type Inconsistency struct {
+	Path          []string        `json:"path"`
+	ExchangeRates []ExchangeRate  `json:"exchangeRates"`
+	Product       decimal.Decimal `json:"product"`
+	Deviation     decimal.Decimal `json:"deviation"`
+}
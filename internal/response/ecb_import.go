@@ -0,0 +1,12 @@
+package response
+
+import "github.com/krios2146/currency-exchange-api-go/internal/model"
+
+type ECBImport struct {
+	From              string           `json:"from"`
+	To                string           `json:"to"`
+	Days              int              `json:"days"`
+	Imported          int              `json:"imported"`
+	Skipped           int              `json:"skipped"`
+	CreatedCurrencies []model.Currency `json:"createdCurrencies"`
+}
@@ -1,11 +1,22 @@
 package response
 
-import "github.com/krios2146/currency-exchange-api-go/internal/model"
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
 
 type Exchange struct {
-	BaseCurrency    model.Currency `json:"baseCurrency"`
-	TargetCurrency  model.Currency `json:"targetCurrency"`
-	Rate            float64        `json:"rate"`
-	Amount          float64        `json:"amount"`
-	ConvertedAmount float64        `json:"convertedAmount"`
+	BaseCurrency    model.Currency  `json:"baseCurrency"`
+	TargetCurrency  model.Currency  `json:"targetCurrency"`
+	Rate            decimal.Decimal `json:"rate"`
+	Amount          decimal.Decimal `json:"amount"`
+	ConvertedAmount decimal.Decimal `json:"convertedAmount"`
+	FeeAmount       decimal.Decimal `json:"feeAmount"`
+	FeeCurrency     string          `json:"feeCurrency"`
+	NetAmount       decimal.Decimal `json:"netAmount"`
+	Path            []string        `json:"path"`
+	Side            model.Side      `json:"side,omitempty"`
+	Spread          decimal.Decimal `json:"spread"`
+	Stale           bool            `json:"stale,omitempty"`
+	Explanation     *Explanation    `json:"explanation,omitempty"`
 }
@@ -0,0 +1,7 @@
+package response
+
+type BatchExchangeItem struct {
+	Status int            `json:"status"`
+	Result *Exchange      `json:"result,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
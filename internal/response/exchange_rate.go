@@ -1,10 +1,25 @@
 package response
 
-import "github.com/krios2146/currency-exchange-api-go/internal/model"
+import (
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
 
 type ExchangeRate struct {
-	Id             int64          `json:"id"`
-	BaseCurrency   model.Currency `json:"baseCurrency"`
-	TargetCurrency model.Currency `json:"targetCurrency"`
-	Rate           float64        `json:"rate"`
+	Id             int64           `json:"id"`
+	BaseCurrency   model.Currency  `json:"baseCurrency"`
+	TargetCurrency model.Currency  `json:"targetCurrency"`
+	Rate           decimal.Decimal `json:"rate"`
+	Bid            decimal.Decimal `json:"bid"`
+	Ask            decimal.Decimal `json:"ask"`
+	EffectiveFrom  time.Time       `json:"effectiveFrom"`
+	Provider       string          `json:"provider,omitempty"`
+	FetchedAt      *time.Time      `json:"fetchedAt,omitempty"`
+	AgeSeconds     *int64          `json:"ageSeconds,omitempty"`
+	Stale          bool            `json:"stale,omitempty"`
+	Sources        []RateSource    `json:"sources,omitempty"`
+	// Percentage by which the accepted source rates differ
+	SourceSpread *decimal.Decimal `json:"sourceSpread,omitempty"`
 }
@@ -0,0 +1,25 @@
+package response
+
+import (
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+type ExchangeRateSeries struct {
+	BaseCurrency   model.Currency       `json:"baseCurrency"`
+	TargetCurrency model.Currency       `json:"targetCurrency"`
+	Interval       string               `json:"interval"`
+	Buckets        []ExchangeRateBucket `json:"buckets"`
+}
+
+type ExchangeRateBucket struct {
+	Start time.Time       `json:"start"`
+	End   time.Time       `json:"end"`
+	Open  decimal.Decimal `json:"open"`
+	High  decimal.Decimal `json:"high"`
+	Low   decimal.Decimal `json:"low"`
+	Close decimal.Decimal `json:"close"`
+	Count int             `json:"count"`
+}
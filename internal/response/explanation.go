@@ -0,0 +1,32 @@
+package response
+
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+type Explanation struct {
+	Method    string              `json:"method"`
+	Legs      []ExplanationLeg    `json:"legs"`
+	Rounding  ExplanationRounding `json:"rounding"`
+	FeeRuleId *int64              `json:"feeRuleId,omitempty"`
+}
+
+type ExplanationLeg struct {
+	ExchangeRateId int64           `json:"exchangeRateId"`
+	From           string          `json:"from"`
+	To             string          `json:"to"`
+	Direction      string          `json:"direction"`
+	Quote          string          `json:"quote"`
+	QuotedRate     decimal.Decimal `json:"quotedRate"`
+	Rate           decimal.Decimal `json:"rate"`
+	PathRate       decimal.Decimal `json:"pathRate"`
+	Amount         decimal.Decimal `json:"amount"`
+}
+
+type ExplanationRounding struct {
+	Mode            model.RoundingMode `json:"mode"`
+	MinorUnits      int32              `json:"minorUnits"`
+	UnroundedAmount decimal.Decimal    `json:"unroundedAmount"`
+	RoundedAmount   decimal.Decimal    `json:"roundedAmount"`
+}
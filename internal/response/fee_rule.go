@@ -0,0 +1,18 @@
+package response
+
+import (
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+type FeeRule struct {
+	Id             int64            `json:"id"`
+	Scope          model.FeeScope   `json:"scope"`
+	Currency       *model.Currency  `json:"currency,omitempty"`
+	BaseCurrency   *model.Currency  `json:"baseCurrency,omitempty"`
+	TargetCurrency *model.Currency  `json:"targetCurrency,omitempty"`
+	Type           model.FeeType    `json:"type"`
+	Amount         *decimal.Decimal `json:"amount,omitempty"`
+	Percentage     *decimal.Decimal `json:"percentage,omitempty"`
+	Tiers          []model.FeeTier  `json:"tiers,omitempty"`
+}
@@ -0,0 +1,10 @@
+package response
+
+import "time"
+
+type Quote struct {
+	Id string `json:"id"`
+	Exchange
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+}
@@ -0,0 +1,26 @@
+package response
+
+import (
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+type RateChange struct {
+	Id             int64                  `json:"id"`
+	Type           model.RateChangeType   `json:"type"`
+	BaseCurrency   model.Currency         `json:"baseCurrency"`
+	TargetCurrency model.Currency         `json:"targetCurrency"`
+	Rate           decimal.Decimal        `json:"rate"`
+	Bid            *decimal.Decimal       `json:"bid,omitempty"`
+	Ask            *decimal.Decimal       `json:"ask,omitempty"`
+	SpreadBps      *decimal.Decimal       `json:"spreadBps,omitempty"`
+	EffectiveFrom  *time.Time             `json:"effectiveFrom,omitempty"`
+	Status         model.RateChangeStatus `json:"status"`
+	ProposedBy     string                 `json:"proposedBy"`
+	ProposedAt     time.Time              `json:"proposedAt"`
+	ReviewedBy     *string                `json:"reviewedBy,omitempty"`
+	ReviewedAt     *time.Time             `json:"reviewedAt,omitempty"`
+	ExchangeRateId *int64                 `json:"exchangeRateId,omitempty"`
+}
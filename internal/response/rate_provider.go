@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+type RateProviderStatus struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastSuccess         *time.Time `json:"lastSuccess"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastErrorAt         *time.Time `json:"lastErrorAt,omitempty"`
+	OpenUntil           *time.Time `json:"openUntil,omitempty"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type RateSource struct {
+	Provider  string          `json:"provider"`
+	Rate      decimal.Decimal `json:"rate"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Rejected  bool            `json:"rejected"`
+}
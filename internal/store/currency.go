@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/krios2146/currency-exchange-api-go/internal/model"
 	"github.com/mattn/go-sqlite3"
@@ -15,8 +17,14 @@ type CurrencyStore struct {
 
 var CurrencyNotFoundError error = errors.New("Currency not found")
 var CurrencyAlreadyExistsError error = errors.New("Currency already exists")
+var CurrencyInUseError error = errors.New("Currency is referenced by exchange rates, their history or fee rules")
 
 var cache = make(map[int64]model.Currency)
+var cacheMu sync.RWMutex
+
+// cacheGeneration changes with every invalidation, a row read before an
+// invalidation isn't cached as it may be stale
+var cacheGeneration uint64
 
 func NewCurrencyStore(db *sql.DB) *CurrencyStore {
 	return &CurrencyStore{
@@ -25,7 +33,7 @@ func NewCurrencyStore(db *sql.DB) *CurrencyStore {
 }
 
 func (s *CurrencyStore) FindAll() ([]model.Currency, error) {
-	rows, err := s.db.Query("SELECT * FROM Currencies;")
+	rows, err := s.db.Query("SELECT id, code, full_name, sign, minor_units, rounding_mode FROM Currencies;")
 
 	if err != nil {
 		slog.Error("SQL Query execution failed", "error", err)
@@ -37,7 +45,14 @@ func (s *CurrencyStore) FindAll() ([]model.Currency, error) {
 
 	for rows.Next() {
 		var currency model.Currency
-		err := rows.Scan(&currency.Id, &currency.Code, &currency.FullName, &currency.Sign)
+		err := rows.Scan(
+			&currency.Id,
+			&currency.Code,
+			&currency.FullName,
+			&currency.Sign,
+			&currency.MinorUnits,
+			&currency.RoundingMode,
+		)
 
 		if err != nil {
 			slog.Error("Unable to map row to model", "error", err)
@@ -51,11 +66,18 @@ func (s *CurrencyStore) FindAll() ([]model.Currency, error) {
 }
 
 func (s *CurrencyStore) FindByCode(code string) (*model.Currency, error) {
-	row := s.db.QueryRow("SELECT * FROM Currencies WHERE code = ?;", code)
+	row := s.db.QueryRow("SELECT id, code, full_name, sign, minor_units, rounding_mode FROM Currencies WHERE code = ?;", code)
 
 	var currency model.Currency
 
-	err := row.Scan(&currency.Id, &currency.Code, &currency.FullName, &currency.Sign)
+	err := row.Scan(
+		&currency.Id,
+		&currency.Code,
+		&currency.FullName,
+		&currency.Sign,
+		&currency.MinorUnits,
+		&currency.RoundingMode,
+	)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, CurrencyNotFoundError
@@ -70,15 +92,25 @@ func (s *CurrencyStore) FindByCode(code string) (*model.Currency, error) {
 }
 
 func (s *CurrencyStore) FindById(id int64) (*model.Currency, error) {
-	if currency, exists := cache[id]; exists {
+	cacheMu.RLock()
+	currency, exists := cache[id]
+	generation := cacheGeneration
+	cacheMu.RUnlock()
+
+	if exists {
 		return &currency, nil
 	}
 
-	row := s.db.QueryRow("SELECT * FROM Currencies WHERE id = ?;", id)
-
-	var currency model.Currency
+	row := s.db.QueryRow("SELECT id, code, full_name, sign, minor_units, rounding_mode FROM Currencies WHERE id = ?;", id)
 
-	err := row.Scan(&currency.Id, &currency.Code, &currency.FullName, &currency.Sign)
+	err := row.Scan(
+		&currency.Id,
+		&currency.Code,
+		&currency.FullName,
+		&currency.Sign,
+		&currency.MinorUnits,
+		&currency.RoundingMode,
+	)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, CurrencyNotFoundError
@@ -89,19 +121,37 @@ func (s *CurrencyStore) FindById(id int64) (*model.Currency, error) {
 		return nil, err
 	}
 
-	cache[id] = currency
+	cacheMu.Lock()
+	if generation == cacheGeneration {
+		cache[id] = currency
+	}
+	cacheMu.Unlock()
 
 	return &currency, nil
 }
 
-func (s *CurrencyStore) Save(name string, code string, sign string) (*model.Currency, error) {
+func (s *CurrencyStore) Save(
+	name string,
+	code string,
+	sign string,
+	minorUnits int32,
+	roundingMode model.RoundingMode,
+) (*model.Currency, error) {
 	row := s.db.QueryRow(
-		"INSERT INTO Currencies (full_name, code, sign) VALUES (?, ?, ?) RETURNING id, code, full_name, sign;",
-		name, code, sign)
+		`INSERT INTO Currencies (full_name, code, sign, minor_units, rounding_mode) VALUES (?, ?, ?, ?, ?)
+		RETURNING id, code, full_name, sign, minor_units, rounding_mode;`,
+		name, code, sign, minorUnits, roundingMode)
 
 	var currency model.Currency
 
-	err := row.Scan(&currency.Id, &currency.Code, &currency.FullName, &currency.Sign)
+	err := row.Scan(
+		&currency.Id,
+		&currency.Code,
+		&currency.FullName,
+		&currency.Sign,
+		&currency.MinorUnits,
+		&currency.RoundingMode,
+	)
 
 	var sqliteErr sqlite3.Error
 	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
@@ -115,3 +165,133 @@ func (s *CurrencyStore) Save(name string, code string, sign string) (*model.Curr
 
 	return &currency, nil
 }
+
+// Update changes the name and sign of the currency
+func (s *CurrencyStore) Update(currency model.Currency) (*model.Currency, error) {
+	row := s.db.QueryRow(
+		`UPDATE Currencies SET full_name = ?, sign = ? WHERE id = ?
+		RETURNING id, code, full_name, sign, minor_units, rounding_mode;`,
+		currency.FullName, currency.Sign, currency.Id,
+	)
+
+	var updated model.Currency
+
+	err := row.Scan(
+		&updated.Id,
+		&updated.Code,
+		&updated.FullName,
+		&updated.Sign,
+		&updated.MinorUnits,
+		&updated.RoundingMode,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, CurrencyNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	invalidate(updated.Id)
+
+	return &updated, nil
+}
+
+// Delete removes the currency. Unless cascading, a currency that is still
+// referenced by exchange rates, their history or fee rules isn't removed.
+// Cascading removes them as well, erasing the history of the currency's pairs,
+// which can't be looked up without it. Rate changes and quotes are kept for the
+// record with the currency codes, open quotes expire.
+func (s *CurrencyStore) Delete(id int64, cascade bool) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if cascade {
+		for _, query := range []string{
+			`DELETE FROM Exchange_rate_sources WHERE history_id IN (
+				SELECT id FROM Exchange_rates_history WHERE base_currency_id = ?1 OR target_currency_id = ?1
+			);`,
+			"DELETE FROM Exchange_rates_history WHERE base_currency_id = ?1 OR target_currency_id = ?1;",
+			"DELETE FROM Exchange_rates WHERE base_currency_id = ?1 OR target_currency_id = ?1;",
+			"DELETE FROM Fee_rules WHERE currency_id = ?1 OR base_currency_id = ?1 OR target_currency_id = ?1;",
+		} {
+			if _, err := tx.Exec(query, id); err != nil {
+				slog.Error("SQL Query execution failed", "error", err)
+				return err
+			}
+		}
+	} else {
+		var referenced bool
+
+		err := tx.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM Exchange_rates WHERE base_currency_id = ?1 OR target_currency_id = ?1)
+				OR EXISTS (SELECT 1 FROM Exchange_rates_history WHERE base_currency_id = ?1 OR target_currency_id = ?1)
+				OR EXISTS (SELECT 1 FROM Fee_rules
+					WHERE currency_id = ?1 OR base_currency_id = ?1 OR target_currency_id = ?1);`,
+			id,
+		).Scan(&referenced)
+
+		if err != nil {
+			slog.Error("SQL Query execution failed", "error", err)
+			return err
+		}
+
+		if referenced {
+			return CurrencyInUseError
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE Quotes SET expires_at = ?2
+		WHERE (base_currency_id = ?1 OR target_currency_id = ?1) AND accepted_at IS NULL AND expires_at > ?2;`,
+		id, formatTime(time.Now()),
+	)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return err
+	}
+
+	result, err := tx.Exec("DELETE FROM Currencies WHERE id = ?;", id)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return err
+	}
+
+	if deleted == 0 {
+		return CurrencyNotFoundError
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return err
+	}
+
+	invalidate(id)
+
+	return nil
+}
+
+// invalidate drops the currency from the cache after it was changed
+func invalidate(id int64) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	delete(cache, id)
+	cacheGeneration++
+}
@@ -4,9 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/krios2146/currency-exchange-api-go/internal/model"
 	"github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
 )
 
 type ExchangeRateStore struct {
@@ -22,72 +25,388 @@ func NewExchangeRateStore(db *sql.DB) *ExchangeRateStore {
 	}
 }
 
+// Rates are read from the history, so that rates scheduled for the future are
+// never returned before they take effect. Exchange_rates keeps a single row
+// per pair with the latest written values. A deleted rate is recorded in the
+// history as removed, so it's still found before it was deleted.
+
 func (s *ExchangeRateStore) FindAll() ([]model.ExchangeRate, error) {
-	rows, err := s.db.Query("SELECT id, base_currency_id, target_currency_id, rate FROM Exchange_rates;")
-	defer rows.Close()
+	return s.FindAllAt(time.Now())
+}
+
+func (s *ExchangeRateStore) FindByCurrencyCodes(baseCurrencyCode string, targetCurrencyCode string) (*model.ExchangeRate, error) {
+	return s.FindByCurrencyCodesAt(baseCurrencyCode, targetCurrencyCode, time.Now())
+}
+
+func (s *ExchangeRateStore) FindByCurrencyCodesAt(
+	baseCurrencyCode string,
+	targetCurrencyCode string,
+	at time.Time,
+) (*model.ExchangeRate, error) {
+	row := s.db.QueryRow(
+		`SELECT exchange_rate_id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, effective_from,
+			provider, fetched_at, id
+		FROM (
+			SELECT h.*
+			FROM Exchange_rates_history h
+			JOIN Currencies bc ON bc.id = h.base_currency_id
+			JOIN Currencies tc ON tc.id = h.target_currency_id
+			WHERE bc.code = ? AND tc.code = ? AND h.effective_from <= ?
+			ORDER BY h.effective_from DESC, h.id DESC
+			LIMIT 1
+		)
+		WHERE NOT removed`,
+		baseCurrencyCode, targetCurrencyCode, formatTime(at),
+	)
+
+	exchangeRate, err := scanExchangeRate(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ExchangeRateNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+// FindLatestByCurrencyCodes returns the rate written last for the pair, which
+// may be scheduled to take effect later
+func (s *ExchangeRateStore) FindLatestByCurrencyCodes(
+	baseCurrencyCode string,
+	targetCurrencyCode string,
+) (*model.ExchangeRate, error) {
+	row := s.db.QueryRow(
+		`SELECT exchange_rate_id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, effective_from,
+			provider, fetched_at, id
+		FROM (
+			SELECT h.*
+			FROM Exchange_rates_history h
+			JOIN Currencies bc ON bc.id = h.base_currency_id
+			JOIN Currencies tc ON tc.id = h.target_currency_id
+			WHERE bc.code = ? AND tc.code = ?
+			ORDER BY h.effective_from DESC, h.id DESC
+			LIMIT 1
+		)
+		WHERE NOT removed`,
+		baseCurrencyCode, targetCurrencyCode,
+	)
+
+	exchangeRate, err := scanExchangeRate(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ExchangeRateNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+func (s *ExchangeRateStore) FindAllAt(at time.Time) ([]model.ExchangeRate, error) {
+	rows, err := s.db.Query(
+		`SELECT exchange_rate_id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, effective_from,
+			provider, fetched_at, id
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (
+				PARTITION BY base_currency_id, target_currency_id
+				ORDER BY effective_from DESC, id DESC
+			) AS version
+			FROM Exchange_rates_history
+			WHERE effective_from <= ?
+		)
+		WHERE version = 1 AND NOT removed
+		ORDER BY exchange_rate_id`,
+		formatTime(at),
+	)
 
 	if err != nil {
 		slog.Error("SQL Query execution failed", "error", err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	var exchangeRates []model.ExchangeRate
 
 	for rows.Next() {
-		var exchangeRate model.ExchangeRate
-		err := rows.Scan(
-			&exchangeRate.Id,
-			&exchangeRate.BaseCurrencyId,
-			&exchangeRate.TargetCurrencyId,
-			&exchangeRate.Rate,
-		)
+		exchangeRate, err := scanExchangeRate(rows)
 
 		if err != nil {
 			slog.Error("Unable to map row to model", "error", err)
 			return nil, err
 		}
 
-		exchangeRates = append(exchangeRates, exchangeRate)
+		exchangeRates = append(exchangeRates, *exchangeRate)
 	}
 
 	return exchangeRates, nil
 }
 
-func (s *ExchangeRateStore) FindByCurrencyCodes(baseCurrencyCode string, targetCurrencyCode string) (*model.ExchangeRate, error) {
-	row := s.db.QueryRow(
-		`SELECT er.id, er.base_currency_id, er.target_currency_id, er.rate FROM Exchange_rates er
-		JOIN Currencies bc ON bc.id = er.base_currency_id
-		JOIN Currencies tc ON tc.id = er.target_currency_id
-		WHERE bc.code = ? AND tc.code = ?`,
-		baseCurrencyCode, targetCurrencyCode,
+// FindScheduled returns the rates that take effect after the given time
+func (s *ExchangeRateStore) FindScheduled(after time.Time) ([]model.ExchangeRate, error) {
+	rows, err := s.db.Query(
+		`SELECT exchange_rate_id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, effective_from,
+			provider, fetched_at, id
+		FROM Exchange_rates_history
+		WHERE effective_from > ?
+		ORDER BY effective_from, id`,
+		formatTime(after),
 	)
 
-	var exchangeRate model.ExchangeRate
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
 
-	err := row.Scan(
-		&exchangeRate.Id,
-		&exchangeRate.BaseCurrencyId,
-		&exchangeRate.TargetCurrencyId,
-		&exchangeRate.Rate,
-	)
+	var exchangeRates []model.ExchangeRate
 
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, ExchangeRateNotFoundError
+	for rows.Next() {
+		exchangeRate, err := scanExchangeRate(rows)
+
+		if err != nil {
+			slog.Error("Unable to map row to model", "error", err)
+			return nil, err
+		}
+
+		exchangeRates = append(exchangeRates, *exchangeRate)
 	}
 
+	return exchangeRates, nil
+}
+
+func (s *ExchangeRateStore) Save(
+	baseCurrencyId int64,
+	targetCurrencyId int64,
+	rate decimal.Decimal,
+	spread model.Spread,
+	effectiveFrom time.Time,
+) (*model.ExchangeRate, error) {
+	tx, err := s.db.Begin()
+
 	if err != nil {
-		slog.Error("Unable to map row to model", "error", err)
+		slog.Error("Unable to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	exchangeRate, err := saveExchangeRate(tx, baseCurrencyId, targetCurrencyId, rate, spread, effectiveFrom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+func (s *ExchangeRateStore) Update(
+	baseCurrencyId int64,
+	targetCurrencyId int64,
+	rate decimal.Decimal,
+	spread model.Spread,
+	effectiveFrom time.Time,
+) (*model.ExchangeRate, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	exchangeRate, err := updateExchangeRate(tx, baseCurrencyId, targetCurrencyId, rate, spread, effectiveFrom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+// Delete removes the exchange rate along with the rates scheduled for it. The
+// removal is recorded in the history with the values in effect at the given
+// time, a rate that isn't in effect yet leaves no record.
+func (s *ExchangeRateStore) Delete(baseCurrencyId int64, targetCurrencyId int64, at time.Time) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := deleteExchangeRate(tx, baseCurrencyId, targetCurrencyId, at); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Upsert creates or updates the exchange rate fetched from rate providers,
+// keeping the quotes of the sources it was aggregated from
+func (s *ExchangeRateStore) Upsert(
+	baseCurrencyId int64,
+	targetCurrencyId int64,
+	rate decimal.Decimal,
+	spread model.Spread,
+	provider string,
+	fetchedAt time.Time,
+	sources []model.RateSource,
+) (*model.ExchangeRate, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	exchangeRate := model.ExchangeRate{
+		BaseCurrencyId:   baseCurrencyId,
+		TargetCurrencyId: targetCurrencyId,
+		Rate:             rate,
+		Spread:           spread,
+		EffectiveFrom:    fetchedAt,
+		Provider:         provider,
+		FetchedAt:        &fetchedAt,
+		Sources:          sources,
+	}
+
+	if err := upsertExchangeRate(tx, &exchangeRate); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
 		return nil, err
 	}
 
 	return &exchangeRate, nil
 }
 
-func (s *ExchangeRateStore) Save(baseCurrencyId int64, targetCurrencyId int64, rate float64) (*model.ExchangeRate, error) {
-	row := s.db.QueryRow(
-		`INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate) VALUES (?, ?, ?)
-		RETURNING id, base_currency_id, target_currency_id, rate`,
-		baseCurrencyId, targetCurrencyId, rate,
+// Import upserts the imported exchange rates in a single transaction, oldest
+// first, and returns how many of them were imported. Rates already recorded
+// in the history for the same pair, time and provider are skipped, so the
+// same file can be imported again.
+func (s *ExchangeRateStore) Import(exchangeRates []model.ExchangeRate) (int, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	sorted := slices.Clone(exchangeRates)
+	slices.SortStableFunc(sorted, func(a, b model.ExchangeRate) int {
+		return a.EffectiveFrom.Compare(b.EffectiveFrom)
+	})
+
+	imported := 0
+
+	for i := range sorted {
+		exchangeRate := &sorted[i]
+
+		var exists bool
+
+		err := tx.QueryRow(
+			`SELECT EXISTS (
+				SELECT 1 FROM Exchange_rates_history
+				WHERE base_currency_id = ? AND target_currency_id = ? AND effective_from = ? AND provider IS ?
+			)`,
+			exchangeRate.BaseCurrencyId, exchangeRate.TargetCurrencyId, formatTime(exchangeRate.EffectiveFrom),
+			sql.NullString{String: exchangeRate.Provider, Valid: len(exchangeRate.Provider) != 0},
+		).Scan(&exists)
+
+		if err != nil {
+			slog.Error("SQL Query execution failed", "error", err)
+			return 0, err
+		}
+		if exists {
+			continue
+		}
+
+		if err := upsertExchangeRate(tx, exchangeRate); err != nil {
+			return 0, err
+		}
+
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return 0, err
+	}
+
+	return imported, nil
+}
+
+// upsertExchangeRate creates or updates the exchange rate and records it in
+// the history. Absolute bid and ask rates can't be kept for a new rate, so they
+// are replaced by the given ones while a spread in basis points is kept.
+func upsertExchangeRate(tx *sql.Tx, exchangeRate *model.ExchangeRate) error {
+	row := tx.QueryRow(
+		`INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate, bid, ask, spread_bps)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (base_currency_id, target_currency_id) DO UPDATE
+		SET rate = excluded.rate,
+			bid = excluded.bid,
+			ask = excluded.ask,
+			spread_bps = CASE WHEN excluded.bid IS NULL THEN COALESCE(excluded.spread_bps, spread_bps) END
+		RETURNING id, bid, ask, spread_bps`,
+		exchangeRate.BaseCurrencyId, exchangeRate.TargetCurrencyId, exchangeRate.Rate,
+		exchangeRate.Bid, exchangeRate.Ask, exchangeRate.SpreadBps,
+	)
+
+	err := row.Scan(
+		&exchangeRate.Id,
+		&exchangeRate.Bid,
+		&exchangeRate.Ask,
+		&exchangeRate.SpreadBps,
+	)
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return err
+	}
+
+	return saveHistory(tx, exchangeRate)
+}
+
+func saveExchangeRate(
+	tx *sql.Tx,
+	baseCurrencyId int64,
+	targetCurrencyId int64,
+	rate decimal.Decimal,
+	spread model.Spread,
+	effectiveFrom time.Time,
+) (*model.ExchangeRate, error) {
+	row := tx.QueryRow(
+		`INSERT INTO Exchange_rates (base_currency_id, target_currency_id, rate, bid, ask, spread_bps)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps`,
+		baseCurrencyId, targetCurrencyId, rate, spread.Bid, spread.Ask, spread.SpreadBps,
 	)
 
 	var exchangeRate model.ExchangeRate
@@ -97,6 +416,9 @@ func (s *ExchangeRateStore) Save(baseCurrencyId int64, targetCurrencyId int64, r
 		&exchangeRate.BaseCurrencyId,
 		&exchangeRate.TargetCurrencyId,
 		&exchangeRate.Rate,
+		&exchangeRate.Bid,
+		&exchangeRate.Ask,
+		&exchangeRate.SpreadBps,
 	)
 
 	var sqliteErr sqlite3.Error
@@ -109,16 +431,29 @@ func (s *ExchangeRateStore) Save(baseCurrencyId int64, targetCurrencyId int64, r
 		return nil, err
 	}
 
+	exchangeRate.EffectiveFrom = effectiveFrom
+
+	if err := saveHistory(tx, &exchangeRate); err != nil {
+		return nil, err
+	}
+
 	return &exchangeRate, nil
 }
 
-func (s *ExchangeRateStore) Update(baseCurrencyId int64, targetCurrencyId int64, rate float64) (*model.ExchangeRate, error) {
-	row := s.db.QueryRow(
+func updateExchangeRate(
+	tx *sql.Tx,
+	baseCurrencyId int64,
+	targetCurrencyId int64,
+	rate decimal.Decimal,
+	spread model.Spread,
+	effectiveFrom time.Time,
+) (*model.ExchangeRate, error) {
+	row := tx.QueryRow(
 		`UPDATE Exchange_rates
-		SET rate = ?
+		SET rate = ?, bid = ?, ask = ?, spread_bps = ?
 		WHERE base_currency_id = ? AND target_currency_id = ?
-		RETURNING id, base_currency_id, target_currency_id, rate`,
-		rate, baseCurrencyId, targetCurrencyId,
+		RETURNING id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps`,
+		rate, spread.Bid, spread.Ask, spread.SpreadBps, baseCurrencyId, targetCurrencyId,
 	)
 
 	var exchangeRate model.ExchangeRate
@@ -128,6 +463,9 @@ func (s *ExchangeRateStore) Update(baseCurrencyId int64, targetCurrencyId int64,
 		&exchangeRate.BaseCurrencyId,
 		&exchangeRate.TargetCurrencyId,
 		&exchangeRate.Rate,
+		&exchangeRate.Bid,
+		&exchangeRate.Ask,
+		&exchangeRate.SpreadBps,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -139,5 +477,236 @@ func (s *ExchangeRateStore) Update(baseCurrencyId int64, targetCurrencyId int64,
 		return nil, err
 	}
 
+	exchangeRate.EffectiveFrom = effectiveFrom
+
+	if err := saveHistory(tx, &exchangeRate); err != nil {
+		return nil, err
+	}
+
+	return &exchangeRate, nil
+}
+
+// deleteExchangeRate removes the exchange rate and returns the id it had
+func deleteExchangeRate(tx *sql.Tx, baseCurrencyId int64, targetCurrencyId int64, at time.Time) (int64, error) {
+	var id int64
+
+	err := tx.QueryRow(
+		"SELECT id FROM Exchange_rates WHERE base_currency_id = ? AND target_currency_id = ?",
+		baseCurrencyId, targetCurrencyId,
+	).Scan(&id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ExchangeRateNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return 0, err
+	}
+
+	for _, query := range []string{
+		// Ids are reused after a delete, so the history is matched by the pair
+		`INSERT INTO Exchange_rates_history
+		(exchange_rate_id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, effective_from, removed)
+		SELECT ?1, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, ?4, 1
+		FROM (
+			SELECT base_currency_id, target_currency_id, rate, bid, ask, spread_bps, removed
+			FROM Exchange_rates_history
+			WHERE base_currency_id = ?2 AND target_currency_id = ?3 AND effective_from <= ?4
+			ORDER BY effective_from DESC, id DESC
+			LIMIT 1
+		)
+		WHERE NOT removed`,
+		`DELETE FROM Exchange_rate_sources WHERE history_id IN (
+			SELECT id FROM Exchange_rates_history
+			WHERE base_currency_id = ?2 AND target_currency_id = ?3 AND effective_from > ?4
+		)`,
+		`DELETE FROM Exchange_rates_history
+		WHERE base_currency_id = ?2 AND target_currency_id = ?3 AND effective_from > ?4`,
+	} {
+		if _, err := tx.Exec(query, id, baseCurrencyId, targetCurrencyId, formatTime(at)); err != nil {
+			slog.Error("Unable to delete exchange rate", "error", err)
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM Exchange_rates WHERE id = ?", id); err != nil {
+		slog.Error("Unable to delete exchange rate", "error", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func saveHistory(tx *sql.Tx, exchangeRate *model.ExchangeRate) error {
+	result, err := tx.Exec(
+		`INSERT INTO Exchange_rates_history
+		(exchange_rate_id, base_currency_id, target_currency_id, rate, bid, ask, spread_bps, effective_from,
+			provider, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		exchangeRate.Id, exchangeRate.BaseCurrencyId, exchangeRate.TargetCurrencyId, exchangeRate.Rate,
+		exchangeRate.Bid, exchangeRate.Ask, exchangeRate.SpreadBps, formatTime(exchangeRate.EffectiveFrom),
+		sql.NullString{String: exchangeRate.Provider, Valid: len(exchangeRate.Provider) != 0},
+		formatNullTime(exchangeRate.FetchedAt),
+	)
+
+	if err != nil {
+		slog.Error("Unable to record exchange rate history", "error", err)
+		return err
+	}
+
+	if exchangeRate.HistoryId, err = result.LastInsertId(); err != nil {
+		slog.Error("Unable to record exchange rate history", "error", err)
+		return err
+	}
+
+	for _, source := range exchangeRate.Sources {
+		_, err := tx.Exec(
+			`INSERT INTO Exchange_rate_sources (history_id, provider, rate, fetched_at, rejected)
+			VALUES (?, ?, ?, ?, ?)`,
+			exchangeRate.HistoryId, source.Provider, source.Rate, formatTime(source.FetchedAt), source.Rejected,
+		)
+
+		if err != nil {
+			slog.Error("Unable to record exchange rate source", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindSources returns the quotes of the rate providers the exchange rate of the
+// history entry was aggregated from
+func (s *ExchangeRateStore) FindSources(historyId int64) ([]model.RateSource, error) {
+	rows, err := s.db.Query(
+		`SELECT provider, rate, fetched_at, rejected
+		FROM Exchange_rate_sources
+		WHERE history_id = ?
+		ORDER BY id`,
+		historyId,
+	)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []model.RateSource
+
+	for rows.Next() {
+		var source model.RateSource
+		var fetchedAt string
+
+		err := rows.Scan(&source.Provider, &source.Rate, &fetchedAt, &source.Rejected)
+
+		if err == nil {
+			source.FetchedAt, err = parseTime(fetchedAt)
+		}
+
+		if err != nil {
+			slog.Error("Unable to map row to model", "error", err)
+			return nil, err
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// FindHistoryByCurrencyCodes returns the rate changes after from and before to,
+// including the removals of the rate. The rate in effect at from is found with
+// FindByCurrencyCodesAt.
+func (s *ExchangeRateStore) FindHistoryByCurrencyCodes(
+	baseCurrencyCode string,
+	targetCurrencyCode string,
+	from time.Time,
+	to time.Time,
+) ([]model.ExchangeRateHistory, error) {
+	rows, err := s.db.Query(
+		`SELECT h.id, h.exchange_rate_id, h.base_currency_id, h.target_currency_id, h.rate, h.effective_from, h.removed
+		FROM Exchange_rates_history h
+		JOIN Currencies bc ON bc.id = h.base_currency_id
+		JOIN Currencies tc ON tc.id = h.target_currency_id
+		WHERE bc.code = ? AND tc.code = ? AND h.effective_from > ? AND h.effective_from < ?
+		ORDER BY h.effective_from, h.id`,
+		baseCurrencyCode, targetCurrencyCode, formatTime(from), formatTime(to),
+	)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.ExchangeRateHistory
+
+	for rows.Next() {
+		var entry model.ExchangeRateHistory
+		var effectiveFrom string
+
+		err := rows.Scan(
+			&entry.Id,
+			&entry.ExchangeRateId,
+			&entry.BaseCurrencyId,
+			&entry.TargetCurrencyId,
+			&entry.Rate,
+			&effectiveFrom,
+			&entry.Removed,
+		)
+
+		if err != nil {
+			slog.Error("Unable to map row to model", "error", err)
+			return nil, err
+		}
+
+		entry.EffectiveFrom, err = parseTime(effectiveFrom)
+
+		if err != nil {
+			slog.Error("Unable to map row to model", "error", err)
+			return nil, err
+		}
+
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+func scanExchangeRate(row scanner) (*model.ExchangeRate, error) {
+	var exchangeRate model.ExchangeRate
+	var effectiveFrom string
+	var provider sql.NullString
+	var fetchedAt sql.NullString
+
+	err := row.Scan(
+		&exchangeRate.Id,
+		&exchangeRate.BaseCurrencyId,
+		&exchangeRate.TargetCurrencyId,
+		&exchangeRate.Rate,
+		&exchangeRate.Bid,
+		&exchangeRate.Ask,
+		&exchangeRate.SpreadBps,
+		&effectiveFrom,
+		&provider,
+		&fetchedAt,
+		&exchangeRate.HistoryId,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exchangeRate.EffectiveFrom, err = parseTime(effectiveFrom); err != nil {
+		return nil, err
+	}
+	if exchangeRate.FetchedAt, err = parseNullTime(fetchedAt); err != nil {
+		return nil, err
+	}
+
+	exchangeRate.Provider = provider.String
+
 	return &exchangeRate, nil
 }
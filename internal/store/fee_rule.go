@@ -0,0 +1,227 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+)
+
+type FeeRuleStore struct {
+	db *sql.DB
+}
+
+var FeeRuleNotFoundError error = errors.New("Fee rule not found")
+
+func NewFeeRuleStore(db *sql.DB) *FeeRuleStore {
+	return &FeeRuleStore{
+		db: db,
+	}
+}
+
+func (s *FeeRuleStore) FindAll() ([]model.FeeRule, error) {
+	rows, err := s.db.Query(
+		`SELECT id, scope, currency_id, base_currency_id, target_currency_id, type, amount, percentage, tiers
+		FROM Fee_rules;`,
+	)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feeRules []model.FeeRule
+
+	for rows.Next() {
+		feeRule, err := scanFeeRule(rows)
+
+		if err != nil {
+			slog.Error("Unable to map row to model", "error", err)
+			return nil, err
+		}
+
+		feeRules = append(feeRules, *feeRule)
+	}
+
+	return feeRules, nil
+}
+
+func (s *FeeRuleStore) FindById(id int64) (*model.FeeRule, error) {
+	row := s.db.QueryRow(
+		`SELECT id, scope, currency_id, base_currency_id, target_currency_id, type, amount, percentage, tiers
+		FROM Fee_rules WHERE id = ?;`,
+		id,
+	)
+
+	feeRule, err := scanFeeRule(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, FeeRuleNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return feeRule, nil
+}
+
+// FindApplicable returns the most specific fee rule for the exchange. Pair
+// rules take precedence over currency rules, which take precedence over
+// global ones.
+func (s *FeeRuleStore) FindApplicable(baseCurrencyId int64, targetCurrencyId int64) (*model.FeeRule, error) {
+	row := s.db.QueryRow(
+		`SELECT id, scope, currency_id, base_currency_id, target_currency_id, type, amount, percentage, tiers
+		FROM Fee_rules
+		WHERE (scope = 'pair' AND base_currency_id = ? AND target_currency_id = ?)
+			OR (scope = 'currency' AND currency_id IN (?, ?))
+			OR scope = 'global'
+		ORDER BY CASE scope WHEN 'pair' THEN 0 WHEN 'currency' THEN 1 ELSE 2 END, id
+		LIMIT 1;`,
+		baseCurrencyId, targetCurrencyId, baseCurrencyId, targetCurrencyId,
+	)
+
+	feeRule, err := scanFeeRule(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, FeeRuleNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return feeRule, nil
+}
+
+func (s *FeeRuleStore) Save(feeRule model.FeeRule) (*model.FeeRule, error) {
+	tiers, err := marshalTiers(feeRule.Tiers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRow(
+		`INSERT INTO Fee_rules
+		(scope, currency_id, base_currency_id, target_currency_id, type, amount, percentage, tiers)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, scope, currency_id, base_currency_id, target_currency_id, type, amount, percentage, tiers;`,
+		feeRule.Scope, feeRule.CurrencyId, feeRule.BaseCurrencyId, feeRule.TargetCurrencyId,
+		feeRule.Type, feeRule.Amount, feeRule.Percentage, tiers,
+	)
+
+	saved, err := scanFeeRule(row)
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (s *FeeRuleStore) Update(feeRule model.FeeRule) (*model.FeeRule, error) {
+	tiers, err := marshalTiers(feeRule.Tiers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRow(
+		`UPDATE Fee_rules
+		SET scope = ?, currency_id = ?, base_currency_id = ?, target_currency_id = ?,
+			type = ?, amount = ?, percentage = ?, tiers = ?
+		WHERE id = ?
+		RETURNING id, scope, currency_id, base_currency_id, target_currency_id, type, amount, percentage, tiers;`,
+		feeRule.Scope, feeRule.CurrencyId, feeRule.BaseCurrencyId, feeRule.TargetCurrencyId,
+		feeRule.Type, feeRule.Amount, feeRule.Percentage, tiers, feeRule.Id,
+	)
+
+	updated, err := scanFeeRule(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, FeeRuleNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *FeeRuleStore) Delete(id int64) error {
+	result, err := s.db.Exec("DELETE FROM Fee_rules WHERE id = ?;", id)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return err
+	}
+
+	if deleted == 0 {
+		return FeeRuleNotFoundError
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFeeRule(row scanner) (*model.FeeRule, error) {
+	var feeRule model.FeeRule
+	var tiers sql.NullString
+
+	err := row.Scan(
+		&feeRule.Id,
+		&feeRule.Scope,
+		&feeRule.CurrencyId,
+		&feeRule.BaseCurrencyId,
+		&feeRule.TargetCurrencyId,
+		&feeRule.Type,
+		&feeRule.Amount,
+		&feeRule.Percentage,
+		&tiers,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tiers.Valid {
+		if err := json.Unmarshal([]byte(tiers.String), &feeRule.Tiers); err != nil {
+			return nil, err
+		}
+	}
+
+	return &feeRule, nil
+}
+
+func marshalTiers(tiers []model.FeeTier) (sql.NullString, error) {
+	if len(tiers) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	value, err := json.Marshal(tiers)
+
+	if err != nil {
+		slog.Error("Unable to map model to row", "error", err)
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(value), Valid: true}, nil
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+)
+
+type QuoteStore struct {
+	db *sql.DB
+}
+
+var QuoteNotFoundError error = errors.New("Quote not found")
+var QuoteExpiredError error = errors.New("Quote has expired")
+var QuoteAlreadyAcceptedError error = errors.New("Quote has already been accepted")
+
+func NewQuoteStore(db *sql.DB) *QuoteStore {
+	return &QuoteStore{
+		db: db,
+	}
+}
+
+func (s *QuoteStore) FindById(id string) (*model.Quote, error) {
+	row := s.db.QueryRow(
+		`SELECT id, base_currency_id, target_currency_id, rate, amount, converted_amount, fee_amount, net_amount,
+			spread, side, path, created_at, expires_at, accepted_at
+		FROM Quotes WHERE id = ?;`,
+		id,
+	)
+
+	quote, err := scanQuote(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, QuoteNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func (s *QuoteStore) Save(quote model.Quote) (*model.Quote, error) {
+	id, err := newQuoteId()
+
+	if err != nil {
+		slog.Error("Unable to generate quote id", "error", err)
+		return nil, err
+	}
+
+	row := s.db.QueryRow(
+		`INSERT INTO Quotes
+		(id, base_currency_id, target_currency_id, rate, amount, converted_amount, fee_amount, net_amount,
+			spread, side, path, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, base_currency_id, target_currency_id, rate, amount, converted_amount, fee_amount, net_amount,
+			spread, side, path, created_at, expires_at, accepted_at;`,
+		id, quote.BaseCurrencyId, quote.TargetCurrencyId, quote.Rate, quote.Amount, quote.ConvertedAmount,
+		quote.FeeAmount, quote.NetAmount, quote.Spread, quote.Side, strings.Join(quote.Path, ","),
+		formatTime(quote.CreatedAt), formatTime(quote.ExpiresAt),
+	)
+
+	saved, err := scanQuote(row)
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// Accept marks the quote as accepted at the given time. A quote can be
+// accepted only once and only before it expires.
+func (s *QuoteStore) Accept(id string, at time.Time) (*model.Quote, error) {
+	row := s.db.QueryRow(
+		`UPDATE Quotes
+		SET accepted_at = ?
+		WHERE id = ? AND accepted_at IS NULL AND expires_at > ?
+		RETURNING id, base_currency_id, target_currency_id, rate, amount, converted_amount, fee_amount, net_amount,
+			spread, side, path, created_at, expires_at, accepted_at;`,
+		formatTime(at), id, formatTime(at),
+	)
+
+	accepted, err := scanQuote(row)
+
+	if err == nil {
+		return accepted, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	quote, err := s.FindById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.AcceptedAt != nil {
+		return nil, QuoteAlreadyAcceptedError
+	}
+
+	return nil, QuoteExpiredError
+}
+
+func scanQuote(row scanner) (*model.Quote, error) {
+	var quote model.Quote
+	var path string
+	var createdAt string
+	var expiresAt string
+	var acceptedAt sql.NullString
+
+	err := row.Scan(
+		&quote.Id,
+		&quote.BaseCurrencyId,
+		&quote.TargetCurrencyId,
+		&quote.Rate,
+		&quote.Amount,
+		&quote.ConvertedAmount,
+		&quote.FeeAmount,
+		&quote.NetAmount,
+		&quote.Spread,
+		&quote.Side,
+		&path,
+		&createdAt,
+		&expiresAt,
+		&acceptedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Path = strings.Split(path, ",")
+
+	if quote.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if quote.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+
+	if acceptedAt.Valid {
+		accepted, err := parseTime(acceptedAt.String)
+
+		if err != nil {
+			return nil, err
+		}
+
+		quote.AcceptedAt = &accepted
+	}
+
+	return &quote, nil
+}
+
+func newQuoteId() (string, error) {
+	id := make([]byte, 16)
+
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
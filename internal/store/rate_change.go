@@ -0,0 +1,324 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+)
+
+type RateChangeStore struct {
+	db *sql.DB
+}
+
+var RateChangeNotFoundError error = errors.New("Rate change not found")
+var RateChangeAlreadyReviewedError error = errors.New("Rate change has already been reviewed")
+var RateChangeSelfReviewError error = errors.New("Rate change cannot be reviewed by the user who proposed it")
+
+func NewRateChangeStore(db *sql.DB) *RateChangeStore {
+	return &RateChangeStore{
+		db: db,
+	}
+}
+
+// FindAll returns the rate changes with the given status, or all of them when
+// the status is empty
+func (s *RateChangeStore) FindAll(status model.RateChangeStatus) ([]model.RateChange, error) {
+	rows, err := s.db.Query(
+		`SELECT id, type, base_currency_id, target_currency_id, base_currency_code, target_currency_code, rate, bid, ask, spread_bps, effective_from,
+			status, proposed_by, proposed_at, reviewed_by, reviewed_at, exchange_rate_id
+		FROM Rate_changes
+		WHERE ? = '' OR status = ?
+		ORDER BY id;`,
+		status, status,
+	)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rateChanges []model.RateChange
+
+	for rows.Next() {
+		rateChange, err := scanRateChange(rows)
+
+		if err != nil {
+			slog.Error("Unable to map row to model", "error", err)
+			return nil, err
+		}
+
+		rateChanges = append(rateChanges, *rateChange)
+	}
+
+	return rateChanges, nil
+}
+
+func (s *RateChangeStore) FindById(id int64) (*model.RateChange, error) {
+	return findRateChange(s.db, id)
+}
+
+func (s *RateChangeStore) Save(rateChange model.RateChange) (*model.RateChange, error) {
+	row := s.db.QueryRow(
+		`INSERT INTO Rate_changes
+		(type, base_currency_id, target_currency_id, base_currency_code, target_currency_code,
+			rate, bid, ask, spread_bps, effective_from, status, proposed_by, proposed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, type, base_currency_id, target_currency_id, base_currency_code, target_currency_code, rate, bid, ask, spread_bps, effective_from,
+			status, proposed_by, proposed_at, reviewed_by, reviewed_at, exchange_rate_id;`,
+		rateChange.Type, rateChange.BaseCurrencyId, rateChange.TargetCurrencyId,
+		rateChange.BaseCurrencyCode, rateChange.TargetCurrencyCode, rateChange.Rate,
+		rateChange.Bid, rateChange.Ask, rateChange.SpreadBps, formatNullTime(rateChange.EffectiveFrom),
+		model.RateChangeStatusPending, rateChange.ProposedBy, formatTime(rateChange.ProposedAt),
+	)
+
+	saved, err := scanRateChange(row)
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// Approve applies the pending rate change to the exchange rates and records
+// who approved it, in a single transaction. A deletion removes the exchange
+// rate on approval.
+func (s *RateChangeStore) Approve(id int64, reviewer string, at time.Time) (*model.RateChange, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rateChange, err := findRateChange(tx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReview(rateChange, reviewer); err != nil {
+		return nil, err
+	}
+
+	// A change approved after its effective time takes effect on approval
+	effectiveFrom := at
+	if rateChange.EffectiveFrom != nil && rateChange.EffectiveFrom.After(at) {
+		effectiveFrom = *rateChange.EffectiveFrom
+	}
+
+	if rateChange.Type != model.RateChangeTypeDelete {
+		if err := checkCurrenciesExist(tx, rateChange.BaseCurrencyId, rateChange.TargetCurrencyId); err != nil {
+			return nil, err
+		}
+	}
+
+	var exchangeRate *model.ExchangeRate
+	var exchangeRateId int64
+
+	switch rateChange.Type {
+	case model.RateChangeTypeCreate:
+		exchangeRate, err = saveExchangeRate(
+			tx, rateChange.BaseCurrencyId, rateChange.TargetCurrencyId, rateChange.Rate, rateChange.Spread, effectiveFrom,
+		)
+	case model.RateChangeTypeDelete:
+		exchangeRateId, err = deleteExchangeRate(tx, rateChange.BaseCurrencyId, rateChange.TargetCurrencyId, effectiveFrom)
+	default:
+		exchangeRate, err = updateExchangeRate(
+			tx, rateChange.BaseCurrencyId, rateChange.TargetCurrencyId, rateChange.Rate, rateChange.Spread, effectiveFrom,
+		)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exchangeRate != nil {
+		exchangeRateId = exchangeRate.Id
+	}
+
+	reviewed, err := reviewRateChange(tx, id, model.RateChangeStatusApproved, reviewer, at, &exchangeRateId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return reviewed, nil
+}
+
+// Reject records who rejected the pending rate change, the exchange rates are
+// left untouched
+func (s *RateChangeStore) Reject(id int64, reviewer string, at time.Time) (*model.RateChange, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		slog.Error("Unable to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rateChange, err := findRateChange(tx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReview(rateChange, reviewer); err != nil {
+		return nil, err
+	}
+
+	reviewed, err := reviewRateChange(tx, id, model.RateChangeStatusRejected, reviewer, at, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Unable to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return reviewed, nil
+}
+
+func checkReview(rateChange *model.RateChange, reviewer string) error {
+	if rateChange.Status != model.RateChangeStatusPending {
+		return RateChangeAlreadyReviewedError
+	}
+	if rateChange.ProposedBy == reviewer {
+		return RateChangeSelfReviewError
+	}
+	return nil
+}
+
+// checkCurrenciesExist guards against applying a change to a currency deleted
+// after the change was proposed
+func checkCurrenciesExist(tx *sql.Tx, baseCurrencyId int64, targetCurrencyId int64) error {
+	var count int
+
+	err := tx.QueryRow(
+		"SELECT COUNT(*) FROM Currencies WHERE id IN (?, ?);", baseCurrencyId, targetCurrencyId,
+	).Scan(&count)
+
+	if err != nil {
+		slog.Error("SQL Query execution failed", "error", err)
+		return err
+	}
+
+	if count != 2 {
+		return CurrencyNotFoundError
+	}
+
+	return nil
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func findRateChange(db queryRower, id int64) (*model.RateChange, error) {
+	row := db.QueryRow(
+		`SELECT id, type, base_currency_id, target_currency_id, base_currency_code, target_currency_code, rate, bid, ask, spread_bps, effective_from,
+			status, proposed_by, proposed_at, reviewed_by, reviewed_at, exchange_rate_id
+		FROM Rate_changes WHERE id = ?;`,
+		id,
+	)
+
+	rateChange, err := scanRateChange(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, RateChangeNotFoundError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return rateChange, nil
+}
+
+func reviewRateChange(
+	tx *sql.Tx,
+	id int64,
+	status model.RateChangeStatus,
+	reviewer string,
+	at time.Time,
+	exchangeRateId *int64,
+) (*model.RateChange, error) {
+	row := tx.QueryRow(
+		`UPDATE Rate_changes
+		SET status = ?, reviewed_by = ?, reviewed_at = ?, exchange_rate_id = ?
+		WHERE id = ? AND status = 'pending'
+		RETURNING id, type, base_currency_id, target_currency_id, base_currency_code, target_currency_code, rate, bid, ask, spread_bps, effective_from,
+			status, proposed_by, proposed_at, reviewed_by, reviewed_at, exchange_rate_id;`,
+		status, reviewer, formatTime(at), exchangeRateId, id,
+	)
+
+	reviewed, err := scanRateChange(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, RateChangeAlreadyReviewedError
+	}
+
+	if err != nil {
+		slog.Error("Unable to map row to model", "error", err)
+		return nil, err
+	}
+
+	return reviewed, nil
+}
+
+func scanRateChange(row scanner) (*model.RateChange, error) {
+	var rateChange model.RateChange
+	var effectiveFrom sql.NullString
+	var proposedAt string
+	var reviewedAt sql.NullString
+
+	err := row.Scan(
+		&rateChange.Id,
+		&rateChange.Type,
+		&rateChange.BaseCurrencyId,
+		&rateChange.TargetCurrencyId,
+		&rateChange.BaseCurrencyCode,
+		&rateChange.TargetCurrencyCode,
+		&rateChange.Rate,
+		&rateChange.Bid,
+		&rateChange.Ask,
+		&rateChange.SpreadBps,
+		&effectiveFrom,
+		&rateChange.Status,
+		&rateChange.ProposedBy,
+		&proposedAt,
+		&rateChange.ReviewedBy,
+		&reviewedAt,
+		&rateChange.ExchangeRateId,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rateChange.EffectiveFrom, err = parseNullTime(effectiveFrom); err != nil {
+		return nil, err
+	}
+	if rateChange.ProposedAt, err = parseTime(proposedAt); err != nil {
+		return nil, err
+	}
+	if rateChange.ReviewedAt, err = parseNullTime(reviewedAt); err != nil {
+		return nil, err
+	}
+
+	return &rateChange, nil
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Timestamps are stored as UTC text of a fixed width, so they can be compared
+// as strings in SQL queries.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseNullTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	t, err := parseTime(value.String)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
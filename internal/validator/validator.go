@@ -4,8 +4,21 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
+
+	"github.com/krios2146/currency-exchange-api-go/internal/iso4217"
+	"github.com/krios2146/currency-exchange-api-go/internal/model"
+	"github.com/shopspring/decimal"
 )
 
+var unknownCurrencyCodesRejected atomic.Bool
+
+// RejectUnknownCurrencyCodes makes ValidateNewCurrencyCode reject codes missing
+// from the ISO 4217 catalog
+func RejectUnknownCurrencyCodes(reject bool) {
+	unknownCurrencyCodesRejected.Store(reject)
+}
+
 func ValidateCurrencyCode(code string) error {
 	if len(code) == 0 {
 		return errors.New("Currency code is not present in the request")
@@ -22,3 +35,128 @@ func ValidateCurrencyCode(code string) error {
 	}
 	return nil
 }
+
+// ValidateNewCurrencyCode validates the code of a currency that is about to be
+// created. Existing currencies stay usable with any code.
+func ValidateNewCurrencyCode(code string) error {
+	if err := ValidateCurrencyCode(code); err != nil {
+		return err
+	}
+	if _, exists := iso4217.Lookup(code); !exists && unknownCurrencyCodesRejected.Load() {
+		return errors.New(fmt.Sprintf("Currency code is not defined in ISO 4217, got: %s", code))
+	}
+	return nil
+}
+
+func ValidateMinorUnits(minorUnits int) error {
+	if minorUnits < 0 || minorUnits > 18 {
+		return errors.New(fmt.Sprintf("Minor units must be between 0 and 18, got: %d", minorUnits))
+	}
+	return nil
+}
+
+func ValidateRoundingMode(roundingMode string) error {
+	for _, mode := range model.RoundingModes {
+		if roundingMode == string(mode) {
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf(
+		"Rounding mode must be one of 'halfEven', 'halfUp', 'floor' or 'ceiling', got: %s", roundingMode,
+	))
+}
+
+func ValidateSide(side string) error {
+	if side != string(model.SideBuy) && side != string(model.SideSell) {
+		return errors.New(fmt.Sprintf("Side must be either 'buy' or 'sell', got: %s", side))
+	}
+	return nil
+}
+
+func ValidateSpread(rate decimal.Decimal, spread model.Spread) error {
+	if spread.SpreadBps.Valid {
+		bps := spread.SpreadBps.Decimal
+		if bps.IsNegative() || bps.GreaterThanOrEqual(decimal.NewFromInt(10000)) {
+			return errors.New(fmt.Sprintf("Spread must be between 0 and 10000 basis points, got: %s", bps))
+		}
+	}
+	if spread.Bid.Valid && spread.Bid.Decimal.Sign() <= 0 {
+		return errors.New("Bid cannot be negative or zero")
+	}
+	if spread.Bid.Valid && spread.Bid.Decimal.GreaterThan(rate) {
+		return errors.New(fmt.Sprintf("Bid cannot be greater than the rate, got: %s", spread.Bid.Decimal))
+	}
+	if spread.Ask.Valid && spread.Ask.Decimal.LessThan(rate) {
+		return errors.New(fmt.Sprintf("Ask cannot be less than the rate, got: %s", spread.Ask.Decimal))
+	}
+	return nil
+}
+
+func ValidateFeeRule(feeRule model.FeeRule) error {
+	switch feeRule.Scope {
+	case model.FeeScopeGlobal:
+	case model.FeeScopeCurrency:
+		if feeRule.CurrencyId == nil {
+			return errors.New("Currency scoped fee rule requires currencyCode")
+		}
+	case model.FeeScopePair:
+		if feeRule.BaseCurrencyId == nil || feeRule.TargetCurrencyId == nil {
+			return errors.New("Pair scoped fee rule requires baseCurrencyCode and targetCurrencyCode")
+		}
+	default:
+		return errors.New(fmt.Sprintf("Scope must be one of 'global', 'currency' or 'pair', got: %s", feeRule.Scope))
+	}
+
+	switch feeRule.Type {
+	case model.FeeTypeFlat:
+		if !feeRule.Amount.Valid {
+			return errors.New("Flat fee rule requires amount")
+		}
+		return validateFeeTier(model.FeeTier{Amount: feeRule.Amount})
+	case model.FeeTypePercentage:
+		if !feeRule.Percentage.Valid {
+			return errors.New("Percentage fee rule requires percentage")
+		}
+		return validateFeeTier(model.FeeTier{Percentage: feeRule.Percentage})
+	case model.FeeTypeTiered:
+		return validateFeeTiers(feeRule.Tiers)
+	default:
+		return errors.New(fmt.Sprintf("Type must be one of 'flat', 'percentage' or 'tiered', got: %s", feeRule.Type))
+	}
+}
+
+func validateFeeTiers(tiers []model.FeeTier) error {
+	if len(tiers) == 0 {
+		return errors.New("Tiered fee rule requires at least one tier")
+	}
+
+	for i, tier := range tiers {
+		if err := validateFeeTier(tier); err != nil {
+			return err
+		}
+		if !tier.UpTo.Valid && i != len(tiers)-1 {
+			return errors.New("Only the last tier can be unbounded")
+		}
+		if tier.UpTo.Valid && i > 0 && !tier.UpTo.Decimal.GreaterThan(tiers[i-1].UpTo.Decimal) {
+			return errors.New("Tiers must be ordered by ascending upTo")
+		}
+	}
+
+	return nil
+}
+
+func validateFeeTier(tier model.FeeTier) error {
+	if !tier.Amount.Valid && !tier.Percentage.Valid {
+		return errors.New("Fee requires an amount or a percentage")
+	}
+	if tier.Amount.Valid && tier.Amount.Decimal.IsNegative() {
+		return errors.New("Fee amount cannot be negative")
+	}
+	if tier.Percentage.Valid {
+		percentage := tier.Percentage.Decimal
+		if percentage.IsNegative() || percentage.GreaterThan(decimal.NewFromInt(100)) {
+			return errors.New(fmt.Sprintf("Fee percentage must be between 0 and 100, got: %s", percentage))
+		}
+	}
+	return nil
+}